@@ -0,0 +1,81 @@
+package dal
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type tagsKey struct{}
+
+// ContextWithTag returns a copy of ctx carrying a tag that TagsFromContext adds to the builders,
+// for instance the route or the trace id of the request.
+func ContextWithTag(ctx context.Context, key, value string) context.Context {
+	tags := make(map[string]string)
+	if parent, ok := ctx.Value(tagsKey{}).(map[string]string); ok {
+		for k, v := range parent {
+			tags[k] = v
+		}
+	}
+	tags[key] = value
+
+	return context.WithValue(ctx, tagsKey{}, tags)
+}
+
+//Tag - adds a key/value pair written as a sqlcommenter comment at the end of the statement
+func (b *Builder) Tag(key, value string) *Builder {
+	if b.tags == nil {
+		b.tags = make(map[string]string)
+	}
+	b.tags[key] = value
+
+	return b
+}
+
+//TagsFromContext - adds the tags stored in ctx with ContextWithTag, explicit tags take precedence
+func (b *Builder) TagsFromContext(ctx context.Context) *Builder {
+	tags, _ := ctx.Value(tagsKey{}).(map[string]string)
+
+	for k, v := range tags {
+		if _, ok := b.tags[k]; !ok {
+			b.Tag(k, v)
+		}
+	}
+
+	return b
+}
+
+func (b *Builder) annotate(sql string) string {
+	if len(b.tags) == 0 {
+		return sql
+	}
+
+	keys := make([]string, 0, len(b.tags))
+	for k := range b.tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = escapeTag(k) + "='" + escapeTag(b.tags[k]) + "'"
+	}
+
+	return sql + " /* " + strings.Join(pairs, ",") + " */"
+}
+
+// escapeTag percent-encodes the value as sqlcommenter does, quotes and stars included, so it can not
+// close the comment or the quotes.
+func escapeTag(v string) string {
+	return strings.Replace(url.QueryEscape(v), "+", "%20", -1)
+}
+
+var tagComment = regexp.MustCompile(` /\* [^*]*\*/$`)
+
+// annotated reports whether query ends with the tags of its builder. The tags often change with every
+// request, so such statements are not kept in the prepared statement cache.
+func annotated(query string) bool {
+	return tagComment.MatchString(query)
+}
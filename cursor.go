@@ -0,0 +1,133 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+)
+
+// Cursor reads the rows of a query one at a time instead of loading the
+// whole result set in memory. It must always be closed.
+type Cursor struct {
+	rows    *sql.Rows
+	columns []string
+	read    int64
+	err     error
+	after   func(rowsAffected int64, err error)
+}
+
+func newCursor(rows *sql.Rows, after func(int64, error)) (*Cursor, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		rows.Close()
+		after(0, err)
+		return nil, err
+	}
+
+	return &Cursor{rows: rows, columns: columns, after: after}, nil
+}
+
+// Columns returns the column names of the result set.
+func (c *Cursor) Columns() []string {
+	return c.columns
+}
+
+// Next prepares the next row to be read, returns false when there are no more rows or an error happened.
+func (c *Cursor) Next() bool {
+	if c.rows.Next() {
+		c.read++
+		return true
+	}
+	return false
+}
+
+// ScanMap reads the current row as a map indexed by column name.
+func (c *Cursor) ScanMap() (map[string]interface{}, error) {
+	values, err := c.ScanArray()
+	if err != nil {
+		return nil, err
+	}
+
+	record := make(map[string]interface{}, len(c.columns))
+	for i, colName := range c.columns {
+		record[colName] = values[i]
+	}
+
+	return record, nil
+}
+
+// ScanArray reads the current row as a slice ordered like the columns.
+func (c *Cursor) ScanArray() ([]interface{}, error) {
+	values := make([]interface{}, len(c.columns))
+	scanArgs := make([]interface{}, len(values))
+	for j := range values {
+		scanArgs[j] = &values[j]
+	}
+
+	if err := c.rows.Scan(scanArgs...); err != nil {
+		c.err = err
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// ScanType reads the current row into o, which must be a pointer to a struct, a scanner or a single value.
+func (c *Cursor) ScanType(o interface{}) error {
+	v := reflect.ValueOf(o)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return errors.New("dal: attempt to load into an invalid pointer")
+	}
+
+	ptr, err := findPtr(c.columns, v.Elem())
+	if err == nil {
+		err = c.rows.Scan(ptr...)
+	}
+
+	if err != nil {
+		c.err = err
+	}
+
+	return err
+}
+
+// Err returns the error, if any, that was encountered during iteration.
+func (c *Cursor) Err() error {
+	return c.rows.Err()
+}
+
+// Close releases the underlying rows, it is safe to call it more than once.
+func (c *Cursor) Close() error {
+	if c.err == nil {
+		c.err = c.rows.Err()
+	}
+
+	err := c.rows.Close()
+
+	if c.after != nil {
+		c.after(c.read, c.err)
+		c.after = nil
+	}
+
+	return err
+}
+
+func iterate(ctx context.Context, handler handlerConn, b Builder) (*Cursor, error) {
+	if err := checkDialect(handler, b); err != nil {
+		return nil, err
+	}
+
+	ctx, after := observe(ctx, handler, b.GetSQL(), b.GetParameters(), b.timeout)
+
+	rows, err := handler.QueryContext(ctx, b.GetSQL(), b.GetParameters()...)
+
+	if err != nil {
+		after(0, err)
+		return nil, wrapError(b.GetSQL(), err)
+	}
+
+	cursor, err := newCursor(rows, after)
+
+	return cursor, wrapError(b.GetSQL(), err)
+}
@@ -0,0 +1,48 @@
+package dal
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursor(t *testing.T) {
+	f := &fakeDB{query: fakeRowsResult([]string{"id", "name"},
+		[]driver.Value{int64(1), "daniel"},
+		[]driver.Value{int64(2), "johan"},
+		[]driver.Value{int64(3), "maria"})}
+
+	sess := newFakeConnection(f).GetSession()
+
+	var b Builder
+	b.Select("id", "name").From("test").Build()
+
+	cursor, err := sess.Iterate(b)
+	assert.NoError(t, err)
+
+	defer cursor.Close()
+
+	assert.True(t, cursor.Next())
+	m, err := cursor.ScanMap()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"id": int64(1), "name": "daniel"}, m)
+
+	assert.True(t, cursor.Next())
+	a, err := cursor.ScanArray()
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{int64(2), "johan"}, a)
+
+	assert.True(t, cursor.Next())
+	var o struct {
+		Id   int64
+		Name string
+	}
+	assert.NoError(t, cursor.ScanType(&o))
+	assert.Equal(t, int64(3), o.Id)
+	assert.Equal(t, "maria", o.Name)
+
+	assert.False(t, cursor.Next())
+	assert.NoError(t, cursor.Err())
+	assert.NoError(t, cursor.Close())
+}
@@ -1,18 +1,43 @@
 package dal
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"sync"
 )
 
 var (
 	_ SessionHandler = (*Transaction)(nil)
 	_ SessionHandler = (*Session)(nil)
+	_ ISession       = (*Transaction)(nil)
+	_ ISession       = (*Session)(nil)
 )
 
 type SessionHandler interface{}
 
 type Connection struct {
+	// mu guards db and stmts, which are replaced by Reconfigure, and the replicas with their policy
+	mu sync.RWMutex
 	db *sql.DB
+	// users counts the statements and transactions running on the pools, Reconfigure waits for them
+	// to finish before closing the pools it replaced
+	users   *sync.WaitGroup
+	txRetry *RetryPolicy
+	stmts   *stmtCache
+	hooks   hookList
+	manager *connectionManager
+	name    string
+	tracer  Tracer
+	// readRetry is applied to the reads of the sessions, and to their writes when retryWrites is set
+	readRetry   *RetryPolicy
+	retryWrites bool
+
+	dialect Dialect
+
+	replicas      []*replica
+	replicaPolicy ReplicaPolicy
+	nextReplica   uint64
 }
 
 type ISession interface {
@@ -27,40 +52,189 @@ type ISession interface {
 	FirstResultArray(b Builder) ([]interface{}, error)
 	FirstResultType(b Builder, o interface{}) error
 	Exec(b Builder) error
+
+	ScanContext(ctx context.Context, b Builder, v ...interface{}) error
+	CountQueryContext(ctx context.Context, b SelectBuilder) ([]map[string]interface{}, int64, error)
+	CountQueryArrayContext(ctx context.Context, b SelectBuilder) ([][]interface{}, int64, error)
+	CountQueryTypeContext(ctx context.Context, b SelectBuilder, o interface{}) (int64, error)
+	QueryContext(ctx context.Context, b Builder) ([]map[string]interface{}, error)
+	QueryArrayContext(ctx context.Context, b Builder) ([][]interface{}, error)
+	QueryTypeContext(ctx context.Context, b Builder, o interface{}) error
+	FirstResultContext(ctx context.Context, b Builder) (map[string]interface{}, error)
+	FirstResultArrayContext(ctx context.Context, b Builder) ([]interface{}, error)
+	FirstResultTypeContext(ctx context.Context, b Builder, o interface{}) error
+	ExecContext(ctx context.Context, b Builder) error
+	ExecResult(b Builder) (*ExecResult, error)
+	ExecResultContext(ctx context.Context, b Builder) (*ExecResult, error)
+	Insert(b Builder, v ...interface{}) error
+	InsertContext(ctx context.Context, b Builder, v ...interface{}) error
+	Iterate(b Builder) (*Cursor, error)
+	IterateContext(ctx context.Context, b Builder) (*Cursor, error)
 }
 
 type handlerConn interface {
-	Exec(query string, args ...interface{}) (sql.Result, error)
-	Query(query string, args ...interface{}) (*sql.Rows, error)
-	QueryRow(query string, args ...interface{}) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
 }
 
 type Session struct {
 	*Connection
 	handler handlerConn
+	primary bool
 }
 
 type Transaction struct {
-	tx      *sql.Tx
-	handler handlerConn
+	tx        *sql.Tx
+	handler   handlerConn
+	parent    *Transaction
+	savepoint string
+	nested    int
+	done      bool
+	readOnly  bool
+	span      Span
+	release   func()
+}
+
+// Wrap returns a connection using a pool owned by the caller, outside of any manager.
+func Wrap(db *sql.DB) *Connection {
+	return &Connection{db: db, users: &sync.WaitGroup{}, dialect: dialectForDriver(fmt.Sprintf("%T", db.Driver()))}
 }
 
 func (c *Connection) GetSession() *Session {
-	return &Session{Connection: c, handler: c.db}
+	return &Session{Connection: c, handler: c.handler(nil, nil, nil)}
+}
+
+func (c *Connection) GetTransaction(opts ...TxOptions) (*Transaction, error) {
+	if len(opts) > 0 {
+		return c.GetTransactionContext(context.Background(), &opts[0])
+	}
+	return c.GetTransactionContext(context.Background(), nil)
 }
 
-func (c *Connection) GetTransaction() (*Transaction, error) {
-	tx, err := c.db.Begin()
+func (c *Connection) GetTransactionContext(ctx context.Context, opts *TxOptions) (*Transaction, error) {
+	ctx, span := c.startSpan(ctx, "dal.transaction")
+
+	// the transaction keeps the pool it started on until it ends, even if Reconfigure replaces it
+	db, stmts, release := c.acquire(false)
+
+	tx, err := db.BeginTx(ctx, opts.txOptions())
 
 	if err != nil {
+		release()
+		endSpan(span, err)
 		return nil, err
 	}
 
-	return &Transaction{tx: tx, handler: tx}, nil
+	if opts != nil && opts.Deferrable {
+		if _, err := tx.ExecContext(ctx, "SET TRANSACTION DEFERRABLE"); err != nil {
+			tx.Rollback()
+			release()
+			endSpan(span, err)
+			return nil, err
+		}
+	}
+
+	return &Transaction{tx: tx, handler: c.handler(tx, stmts, span), span: span, readOnly: opts != nil && opts.ReadOnly, release: release}, nil
+}
+
+func (c *Connection) getDB() *sql.DB {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.db
+}
+
+// Close closes the prepared statements, the replicas and the pool of the connection.
+func (c *Connection) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stmts != nil {
+		c.stmts.close()
+		c.stmts = nil
+	}
+
+	for _, r := range c.replicas {
+		if r.stmts != nil {
+			r.stmts.close()
+		}
+		r.db.Close()
+	}
+	c.replicas = nil
+
+	return c.db.Close()
+}
+
+// acquire returns the pool and the statement cache in use, those of a replica for a read when there
+// are, with the function to call once done with them so Reconfigure can close them.
+func (c *Connection) acquire(read bool) (*sql.DB, *stmtCache, func()) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	db, stmts := c.db, c.stmts
+	if read {
+		if r := c.pickReplica(); r != nil {
+			db, stmts = r.db, r.stmts
+		}
+	}
+
+	users := c.users
+	users.Add(1)
+
+	var once sync.Once
+	return db, stmts, func() { once.Do(users.Done) }
+}
+
+// swap replaces the pool and the replicas of the connection, it returns the function closing the
+// previous ones once the sessions and transactions using them are done.
+func (c *Connection) swap(db *sql.DB, replicas []*replica, policy ReplicaPolicy) func() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	old, stmts, oldReplicas, users := c.db, c.stmts, c.replicas, c.users
+
+	c.db = db
+	c.replicas = replicas
+	c.replicaPolicy = policy
+	c.users = &sync.WaitGroup{}
+
+	if stmts != nil {
+		c.stmts = newStmtCache(db, stmts.capacity)
+		for _, r := range replicas {
+			r.stmts = newStmtCache(r.db, stmts.capacity)
+		}
+	}
+
+	return func() {
+		users.Wait()
+
+		if stmts != nil {
+			stmts.close()
+		}
+		old.Close()
+
+		for _, r := range oldReplicas {
+			if r.stmts != nil {
+				r.stmts.close()
+			}
+			r.db.Close()
+		}
+	}
+}
+
+// IsReadOnly reports whether the transaction was started as READ ONLY.
+func (tx *Transaction) IsReadOnly() bool {
+	return tx.readOnly
 }
 
 func (tx *Transaction) Commit() error {
+	if tx.parent != nil {
+		return tx.releaseNested()
+	}
+
 	err := tx.tx.Commit()
+	tx.release()
+	tx.endSpan(false, err)
 	if err != nil {
 		return err
 	}
@@ -68,10 +242,17 @@ func (tx *Transaction) Commit() error {
 }
 
 func (tx *Transaction) Rollback() error {
+	if tx.parent != nil {
+		return tx.rollbackNested()
+	}
+
 	err := tx.tx.Rollback()
+	tx.release()
 	if err != nil && err != sql.ErrTxDone {
+		tx.endSpan(true, err)
 		return err
 	}
+	tx.endSpan(true, nil)
 
 	return nil
 }
@@ -81,11 +262,29 @@ SCAN
 */
 
 func (s *Session) Scan(b Builder, v ...interface{}) error {
-	return scan(s.handler, b, v...)
+	return s.ScanContext(context.Background(), b, v...)
+}
+
+func (s *Session) ScanContext(ctx context.Context, b Builder, v ...interface{}) error {
+	return s.retryFor(ctx, b, func() error {
+		return scan(ctx, s.handlerFor(b), b, v...)
+	})
 }
 
 func (t *Transaction) Scan(b Builder, v ...interface{}) error {
-	return scan(t.handler, b, v...)
+	if err := t.checkWritable(b); err != nil {
+		return err
+	}
+
+	return scan(context.Background(), t.handler, b, v...)
+}
+
+func (t *Transaction) ScanContext(ctx context.Context, b Builder, v ...interface{}) error {
+	if err := t.checkWritable(b); err != nil {
+		return err
+	}
+
+	return scan(ctx, t.handler, b, v...)
 }
 
 /**
@@ -93,27 +292,98 @@ COUNT QUERY
 */
 
 func (s *Session) CountQuery(b SelectBuilder) ([]map[string]interface{}, int64, error) {
-	return countQuery(s.handler, b)
+	return s.CountQueryContext(context.Background(), b)
+}
+
+func (s *Session) CountQueryContext(ctx context.Context, b SelectBuilder) (result []map[string]interface{}, count int64, err error) {
+	err = s.retryFor(ctx, *b.b, func() (err error) {
+		handler, release := s.pinnedHandlerFor(*b.b)
+		defer release()
+
+		result, count, err = countQuery(ctx, handler, b)
+		return
+	})
+	return
 }
 
 func (t *Transaction) CountQuery(b SelectBuilder) ([]map[string]interface{}, int64, error) {
-	return countQuery(t.handler, b)
+	if err := t.checkWritable(*b.b); err != nil {
+		return nil, 0, err
+	}
+
+	return countQuery(context.Background(), t.handler, b)
+}
+
+func (t *Transaction) CountQueryContext(ctx context.Context, b SelectBuilder) ([]map[string]interface{}, int64, error) {
+	if err := t.checkWritable(*b.b); err != nil {
+		return nil, 0, err
+	}
+
+	return countQuery(ctx, t.handler, b)
 }
 
 func (s *Session) CountQueryArray(b SelectBuilder) ([][]interface{}, int64, error) {
-	return countQueryArray(s.handler, b)
+	return s.CountQueryArrayContext(context.Background(), b)
+}
+
+func (s *Session) CountQueryArrayContext(ctx context.Context, b SelectBuilder) (result [][]interface{}, count int64, err error) {
+	err = s.retryFor(ctx, *b.b, func() (err error) {
+		handler, release := s.pinnedHandlerFor(*b.b)
+		defer release()
+
+		result, count, err = countQueryArray(ctx, handler, b)
+		return
+	})
+	return
 }
 
 func (t *Transaction) CountQueryArray(b SelectBuilder) ([][]interface{}, int64, error) {
-	return countQueryArray(t.handler, b)
+	if err := t.checkWritable(*b.b); err != nil {
+		return nil, 0, err
+	}
+
+	return countQueryArray(context.Background(), t.handler, b)
+}
+
+func (t *Transaction) CountQueryArrayContext(ctx context.Context, b SelectBuilder) ([][]interface{}, int64, error) {
+	if err := t.checkWritable(*b.b); err != nil {
+		return nil, 0, err
+	}
+
+	return countQueryArray(ctx, t.handler, b)
 }
 
 func (s *Session) CountQueryType(b SelectBuilder, o interface{}) (int64, error) {
-	return countQueryType(s.handler, b, o)
+	return s.CountQueryTypeContext(context.Background(), b, o)
+}
+
+func (s *Session) CountQueryTypeContext(ctx context.Context, b SelectBuilder, o interface{}) (count int64, err error) {
+	reset := restoreTarget(o)
+	err = s.retryFor(ctx, *b.b, func() (err error) {
+		reset()
+		handler, release := s.pinnedHandlerFor(*b.b)
+		defer release()
+
+		count, err = countQueryType(ctx, handler, b, o)
+		return
+	})
+	return
 }
 
 func (t *Transaction) CountQueryType(b SelectBuilder, o interface{}) (int64, error) {
-	return countQueryType(t.handler, b, o)
+	if err := t.checkWritable(*b.b); err != nil {
+		return 0, err
+	}
+
+	return countQueryType(context.Background(), t.handler, b, o)
+}
+
+func (t *Transaction) CountQueryTypeContext(ctx context.Context, b SelectBuilder, o interface{}) (int64, error) {
+	if err := t.checkWritable(*b.b); err != nil {
+		return 0, err
+	}
+
+	return countQueryType(ctx, t.handler, b, o)
 }
 
 /**
@@ -121,27 +391,87 @@ QUERY
 */
 
 func (s *Session) Query(b Builder) ([]map[string]interface{}, error) {
-	return query(s.handler, b)
+	return s.QueryContext(context.Background(), b)
+}
+
+func (s *Session) QueryContext(ctx context.Context, b Builder) (result []map[string]interface{}, err error) {
+	err = s.retryFor(ctx, b, func() (err error) {
+		result, err = query(ctx, s.handlerFor(b), b)
+		return
+	})
+	return
 }
 
 func (t *Transaction) Query(b Builder) ([]map[string]interface{}, error) {
-	return query(t.handler, b)
+	if err := t.checkWritable(b); err != nil {
+		return nil, err
+	}
+
+	return query(context.Background(), t.handler, b)
+}
+
+func (t *Transaction) QueryContext(ctx context.Context, b Builder) ([]map[string]interface{}, error) {
+	if err := t.checkWritable(b); err != nil {
+		return nil, err
+	}
+
+	return query(ctx, t.handler, b)
 }
 
 func (s *Session) QueryArray(b Builder) ([][]interface{}, error) {
-	return queryArray(s.handler, b)
+	return s.QueryArrayContext(context.Background(), b)
+}
+
+func (s *Session) QueryArrayContext(ctx context.Context, b Builder) (result [][]interface{}, err error) {
+	err = s.retryFor(ctx, b, func() (err error) {
+		result, err = queryArray(ctx, s.handlerFor(b), b)
+		return
+	})
+	return
 }
 
 func (t *Transaction) QueryArray(b Builder) ([][]interface{}, error) {
-	return queryArray(t.handler, b)
+	if err := t.checkWritable(b); err != nil {
+		return nil, err
+	}
+
+	return queryArray(context.Background(), t.handler, b)
+}
+
+func (t *Transaction) QueryArrayContext(ctx context.Context, b Builder) ([][]interface{}, error) {
+	if err := t.checkWritable(b); err != nil {
+		return nil, err
+	}
+
+	return queryArray(ctx, t.handler, b)
 }
 
 func (s *Session) QueryType(b Builder, o interface{}) error {
-	return queryType(s.handler, b, o)
+	return s.QueryTypeContext(context.Background(), b, o)
+}
+
+func (s *Session) QueryTypeContext(ctx context.Context, b Builder, o interface{}) error {
+	reset := restoreTarget(o)
+	return s.retryFor(ctx, b, func() error {
+		reset()
+		return queryType(ctx, s.handlerFor(b), b, o)
+	})
 }
 
 func (t *Transaction) QueryType(b Builder, o interface{}) error {
-	return queryType(t.handler, b, o)
+	if err := t.checkWritable(b); err != nil {
+		return err
+	}
+
+	return queryType(context.Background(), t.handler, b, o)
+}
+
+func (t *Transaction) QueryTypeContext(ctx context.Context, b Builder, o interface{}) error {
+	if err := t.checkWritable(b); err != nil {
+		return err
+	}
+
+	return queryType(ctx, t.handler, b, o)
 }
 
 /**
@@ -149,27 +479,113 @@ FIRST
 */
 
 func (s *Session) FirstResult(b Builder) (map[string]interface{}, error) {
-	return firstResult(s.handler, b)
+	return s.FirstResultContext(context.Background(), b)
+}
+
+func (s *Session) FirstResultContext(ctx context.Context, b Builder) (result map[string]interface{}, err error) {
+	err = s.retryFor(ctx, b, func() (err error) {
+		result, err = firstResult(ctx, s.handlerFor(b), b)
+		return
+	})
+	return
 }
 
 func (t *Transaction) FirstResult(b Builder) (map[string]interface{}, error) {
-	return firstResult(t.handler, b)
+	if err := t.checkWritable(b); err != nil {
+		return nil, err
+	}
+
+	return firstResult(context.Background(), t.handler, b)
+}
+
+func (t *Transaction) FirstResultContext(ctx context.Context, b Builder) (map[string]interface{}, error) {
+	if err := t.checkWritable(b); err != nil {
+		return nil, err
+	}
+
+	return firstResult(ctx, t.handler, b)
 }
 
 func (s *Session) FirstResultArray(b Builder) ([]interface{}, error) {
-	return firstResultArray(s.handler, b)
+	return s.FirstResultArrayContext(context.Background(), b)
+}
+
+func (s *Session) FirstResultArrayContext(ctx context.Context, b Builder) (result []interface{}, err error) {
+	err = s.retryFor(ctx, b, func() (err error) {
+		result, err = firstResultArray(ctx, s.handlerFor(b), b)
+		return
+	})
+	return
 }
 
 func (t *Transaction) FirstResultArray(b Builder) ([]interface{}, error) {
-	return firstResultArray(t.handler, b)
+	if err := t.checkWritable(b); err != nil {
+		return nil, err
+	}
+
+	return firstResultArray(context.Background(), t.handler, b)
+}
+
+func (t *Transaction) FirstResultArrayContext(ctx context.Context, b Builder) ([]interface{}, error) {
+	if err := t.checkWritable(b); err != nil {
+		return nil, err
+	}
+
+	return firstResultArray(ctx, t.handler, b)
 }
 
 func (s *Session) FirstResultType(b Builder, o interface{}) error {
-	return firstResultType(s.handler, b, o)
+	return s.FirstResultTypeContext(context.Background(), b, o)
+}
+
+func (s *Session) FirstResultTypeContext(ctx context.Context, b Builder, o interface{}) error {
+	return s.retryFor(ctx, b, func() error {
+		return firstResultType(ctx, s.handlerFor(b), b, o)
+	})
 }
 
 func (t *Transaction) FirstResultType(b Builder, o interface{}) error {
-	return firstResultType(t.handler, b, o)
+	if err := t.checkWritable(b); err != nil {
+		return err
+	}
+
+	return firstResultType(context.Background(), t.handler, b, o)
+}
+
+func (t *Transaction) FirstResultTypeContext(ctx context.Context, b Builder, o interface{}) error {
+	if err := t.checkWritable(b); err != nil {
+		return err
+	}
+
+	return firstResultType(ctx, t.handler, b, o)
+}
+
+/**
+ITERATE
+*/
+
+func (s *Session) Iterate(b Builder) (*Cursor, error) {
+	return s.IterateContext(context.Background(), b)
+}
+
+func (s *Session) IterateContext(ctx context.Context, b Builder) (*Cursor, error) {
+	return iterate(ctx, s.handlerFor(b), b)
+}
+
+func (t *Transaction) Iterate(b Builder) (*Cursor, error) {
+	if err := t.checkWritable(b); err != nil {
+		return nil, err
+	}
+
+	return iterate(context.Background(), t.handler, b)
+}
+
+func (t *Transaction) IterateContext(ctx context.Context, b Builder) (*Cursor, error) {
+	if err := t.checkWritable(b); err != nil {
+		return nil, err
+	}
+
+	return iterate(ctx, t.handler, b)
 }
 
 /**
@@ -177,121 +593,184 @@ Exec
 */
 
 func (s *Session) Exec(b Builder) error {
-	return exec(s.handler, b)
+	return s.ExecContext(context.Background(), b)
+}
+
+func (s *Session) ExecContext(ctx context.Context, b Builder) error {
+	return s.retryExec(ctx, func() error {
+		return exec(ctx, s.handler, b)
+	})
 }
 
 func (t *Transaction) Exec(b Builder) error {
-	return exec(t.handler, b)
+	if err := t.checkWritable(b); err != nil {
+		return err
+	}
+
+	return exec(context.Background(), t.handler, b)
+}
+
+func (t *Transaction) ExecContext(ctx context.Context, b Builder) error {
+	if err := t.checkWritable(b); err != nil {
+		return err
+	}
+
+	return exec(ctx, t.handler, b)
+}
+
+func (s *Session) ExecResult(b Builder) (*ExecResult, error) {
+	return s.ExecResultContext(context.Background(), b)
+}
+
+func (s *Session) ExecResultContext(ctx context.Context, b Builder) (result *ExecResult, err error) {
+	err = s.retryExec(ctx, func() (err error) {
+		result, err = execResult(ctx, s.handler, b)
+		return
+	})
+	return
+}
+
+func (t *Transaction) ExecResult(b Builder) (*ExecResult, error) {
+	if err := t.checkWritable(b); err != nil {
+		return nil, err
+	}
+
+	return execResult(context.Background(), t.handler, b)
+}
+
+func (t *Transaction) ExecResultContext(ctx context.Context, b Builder) (*ExecResult, error) {
+	if err := t.checkWritable(b); err != nil {
+		return nil, err
+	}
+
+	return execResult(ctx, t.handler, b)
 }
 
 /**
-Private Methods
+INSERT
 */
 
-func scan(handler handlerConn, b Builder, v ...interface{}) error {
-	return handler.QueryRow(b.GetSQL(), b.GetParameters()...).Scan(v...)
+func (s *Session) Insert(b Builder, v ...interface{}) error {
+	return s.InsertContext(context.Background(), b, v...)
 }
 
-func countQuery(handler handlerConn, b SelectBuilder) ([]map[string]interface{}, int64, error) {
-	var count int64
-
-	handler.QueryRow(b.GetCountSQL(), b.b.GetParameters()...).Scan(&count)
+func (s *Session) InsertContext(ctx context.Context, b Builder, v ...interface{}) error {
+	return s.retryExec(ctx, func() error {
+		return insertReturning(ctx, s.handler, b, v...)
+	})
+}
 
-	if count == 0 {
-		return make([]map[string]interface{}, 0), 0, nil
+func (t *Transaction) Insert(b Builder, v ...interface{}) error {
+	if err := t.checkWritable(b); err != nil {
+		return err
 	}
 
-	rows, err := handler.Query(b.b.GetSQL(), b.b.GetParameters()...)
+	return insertReturning(context.Background(), t.handler, b, v...)
+}
 
-	if err != nil {
-		return nil, 0, err
+func (t *Transaction) InsertContext(ctx context.Context, b Builder, v ...interface{}) error {
+	if err := t.checkWritable(b); err != nil {
+		return err
 	}
 
-	defer rows.Close()
+	return insertReturning(ctx, t.handler, b, v...)
+}
 
-	columns, _ := rows.Columns()
+/**
+Private Methods
+*/
 
-	values := make([]interface{}, len(columns))
-	scanArgs := make([]interface{}, len(values))
-	for j := range values {
-		scanArgs[j] = &values[j]
+func scan(ctx context.Context, handler handlerConn, b Builder, v ...interface{}) error {
+	if err := checkDialect(handler, b); err != nil {
+		return err
 	}
 
-	result := []map[string]interface{}{}
+	ctx, after := observe(ctx, handler, b.GetSQL(), b.GetParameters(), b.timeout)
 
-	for rows.Next() {
-		record := make(map[string]interface{})
+	err := handler.QueryRowContext(ctx, b.GetSQL(), b.GetParameters()...).Scan(v...)
 
-		rows.Scan(scanArgs...)
-		for i, colName := range columns {
-			record[string(colName)] = values[i]
-		}
+	after(scannedRows(err), err)
 
-		result = append(result, record)
+	if err == sql.ErrNoRows {
+		return err
 	}
 
-	return result, count, nil
+	return wrapError(b.GetSQL(), err)
 }
 
-func countQueryArray(handler handlerConn, b SelectBuilder) ([][]interface{}, int64, error) {
+func selectCount(ctx context.Context, handler handlerConn, b SelectBuilder) (int64, error) {
+	if err := checkDialect(handler, *b.b); err != nil {
+		return 0, err
+	}
+
 	var count int64
 
-	handler.QueryRow(b.GetCountSQL(), b.b.GetParameters()...).Scan(&count)
+	q := b.GetCountSQL()
 
-	if count == 0 {
-		return make([][]interface{}, 0), 0, nil
-	}
+	ctx, after := observe(ctx, handler, q, b.b.GetParameters(), b.b.timeout)
+
+	err := handler.QueryRowContext(ctx, q, b.b.GetParameters()...).Scan(&count)
 
-	rows, err := handler.Query(b.b.GetSQL(), b.b.GetParameters()...)
+	after(scannedRows(err), err)
+
+	return count, wrapError(q, err)
+}
 
+func scannedRows(err error) int64 {
 	if err != nil {
-		return nil, 0, err
+		return 0
 	}
+	return 1
+}
 
-	defer rows.Close()
-
-	columns, _ := rows.Columns()
+func countQuery(ctx context.Context, handler handlerConn, b SelectBuilder) ([]map[string]interface{}, int64, error) {
+	count, err := selectCount(ctx, handler, b)
 
-	values := make([]interface{}, len(columns))
-	scanArgs := make([]interface{}, len(values))
-	for j := range values {
-		scanArgs[j] = &values[j]
+	if err != nil {
+		return nil, 0, err
 	}
 
-	result := make([][]interface{}, 0)
-
-	for rows.Next() {
-		record := make([]interface{}, len(columns))
+	if count == 0 {
+		return make([]map[string]interface{}, 0), 0, nil
+	}
 
-		rows.Scan(scanArgs...)
-		for i, _ := range columns {
-			record[i] = values[i]
-		}
+	result, err := query(ctx, handler, *b.b)
 
-		result = append(result, record)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	return result, 0, nil
+	return result, count, nil
 }
 
-func countQueryType(handler handlerConn, b SelectBuilder, d interface{}) (int64, error) {
-	var count int64
+func countQueryArray(ctx context.Context, handler handlerConn, b SelectBuilder) ([][]interface{}, int64, error) {
+	count, err := selectCount(ctx, handler, b)
 
-	err := handler.QueryRow(b.GetCountSQL(), b.GetBuilder().GetParameters()...).Scan(&count)
+	if err != nil {
+		return nil, 0, err
+	}
 
 	if count == 0 {
-		return 0, err
+		return make([][]interface{}, 0), 0, nil
 	}
 
-	rows, err := handler.Query(b.b.GetSQL(), b.GetBuilder().GetParameters()...)
+	result, err := queryArray(ctx, handler, *b.b)
 
 	if err != nil {
-		return 0, err
+		return nil, 0, err
 	}
 
-	defer rows.Close()
+	return result, count, nil
+}
+
+func countQueryType(ctx context.Context, handler handlerConn, b SelectBuilder, d interface{}) (int64, error) {
+	count, err := selectCount(ctx, handler, b)
 
-	_, err = Load(rows, d)
+	if err != nil || count == 0 {
+		return 0, err
+	}
+
+	err = queryType(ctx, handler, *b.b, d)
 
 	if err != nil {
 		return 0, err
@@ -300,173 +779,155 @@ func countQueryType(handler handlerConn, b SelectBuilder, d interface{}) (int64,
 	return count, nil
 }
 
-func query(handler handlerConn, b Builder) ([]map[string]interface{}, error) {
-	rows, err := handler.Query(b.GetSQL(), b.GetParameters()...)
+func query(ctx context.Context, handler handlerConn, b Builder) ([]map[string]interface{}, error) {
+	cursor, err := iterate(ctx, handler, b)
 
 	if err != nil {
 		return nil, err
 	}
 
-	defer rows.Close()
-
-	columns, _ := rows.Columns()
-
-	values := make([]interface{}, len(columns))
-	scanArgs := make([]interface{}, len(values))
-	for j := range values {
-		scanArgs[j] = &values[j]
-	}
+	defer cursor.Close()
 
 	result := []map[string]interface{}{}
 
-	for rows.Next() {
-		record := make(map[string]interface{})
+	for cursor.Next() {
+		record, err := cursor.ScanMap()
 
-		rows.Scan(scanArgs...)
-		for i, colName := range columns {
-			record[string(colName)] = values[i]
+		if err != nil {
+			return nil, wrapError(b.GetSQL(), err)
 		}
 
 		result = append(result, record)
 	}
 
+	if err := cursor.Err(); err != nil {
+		return nil, wrapError(b.GetSQL(), err)
+	}
+
 	return result, nil
 }
 
-func queryArray(handler handlerConn, b Builder) ([][]interface{}, error) {
-	rows, err := handler.Query(b.GetSQL(), b.GetParameters()...)
+func queryArray(ctx context.Context, handler handlerConn, b Builder) ([][]interface{}, error) {
+	cursor, err := iterate(ctx, handler, b)
 
 	if err != nil {
 		return nil, err
 	}
 
-	defer rows.Close()
-
-	columns, _ := rows.Columns()
-
-	values := make([]interface{}, len(columns))
-	scanArgs := make([]interface{}, len(values))
-	for j := range values {
-		scanArgs[j] = &values[j]
-	}
+	defer cursor.Close()
 
 	result := make([][]interface{}, 0)
 
-	for rows.Next() {
-		record := make([]interface{}, len(columns))
+	for cursor.Next() {
+		record, err := cursor.ScanArray()
 
-		rows.Scan(scanArgs...)
-		for i, _ := range columns {
-			record[i] = values[i]
+		if err != nil {
+			return nil, wrapError(b.GetSQL(), err)
 		}
 
 		result = append(result, record)
 	}
 
+	if err := cursor.Err(); err != nil {
+		return nil, wrapError(b.GetSQL(), err)
+	}
+
 	return result, nil
 }
 
-func queryType(handler handlerConn, b Builder, d interface{}) error {
-	rows, err := handler.Query(b.GetSQL(), b.GetParameters()...)
+func queryType(ctx context.Context, handler handlerConn, b Builder, d interface{}) error {
+	return loadType(ctx, handler, b, d, false)
+}
 
-	if err != nil {
+func loadType(ctx context.Context, handler handlerConn, b Builder, d interface{}, oneResult bool) (err error) {
+	if err := checkDialect(handler, b); err != nil {
 		return err
 	}
 
-	defer rows.Close()
+	ctx, after := observe(ctx, handler, b.GetSQL(), b.GetParameters(), b.timeout)
+
+	count := 0
+	defer func() {
+		after(int64(count), err)
+	}()
 
-	_, err = Load(rows, d)
+	rows, err := handler.QueryContext(ctx, b.GetSQL(), b.GetParameters()...)
 
 	if err != nil {
-		return err
+		return wrapError(b.GetSQL(), err)
 	}
 
-	return nil
-}
+	defer rows.Close()
 
-func exec(handler handlerConn, b Builder) (err error) {
-	_, err = handler.Exec(b.GetSQL(), b.GetParameters()...)
+	count, err = load(rows, d, oneResult)
 
-	return
+	return wrapError(b.GetSQL(), err)
 }
 
-func firstResult(handler handlerConn, b Builder) (map[string]interface{}, error) {
-	rows, err := handler.Query(b.GetSQL(), b.GetParameters()...)
-
-	if err != nil {
-		return nil, err
+func exec(ctx context.Context, handler handlerConn, b Builder) error {
+	if err := checkDialect(handler, b); err != nil {
+		return err
 	}
 
-	defer rows.Close()
+	ctx, after := observe(ctx, handler, b.GetSQL(), b.GetParameters(), b.timeout)
 
-	columns, _ := rows.Columns()
+	res, err := handler.ExecContext(ctx, b.GetSQL(), b.GetParameters()...)
 
-	values := make([]interface{}, len(columns))
-	scanArgs := make([]interface{}, len(values))
-	for j := range values {
-		scanArgs[j] = &values[j]
-	}
+	after(affectedRows(res, err), err)
 
-	if rows.Next() {
-		result := make(map[string]interface{})
+	return wrapError(b.GetSQL(), err)
+}
 
-		rows.Scan(scanArgs...)
-		for i, colName := range columns {
-			result[string(colName)] = values[i]
-		}
+func affectedRows(res sql.Result, err error) int64 {
+	if err != nil {
+		return 0
+	}
+
+	rows, err := res.RowsAffected()
 
-		return result, nil
-	} else {
-		return nil, nil
+	if err != nil {
+		return -1
 	}
 
+	return rows
 }
 
-func firstResultArray(handler handlerConn, b Builder) ([]interface{}, error) {
-	rows, err := handler.Query(b.GetSQL(), b.GetParameters()...)
+func firstResult(ctx context.Context, handler handlerConn, b Builder) (map[string]interface{}, error) {
+	cursor, err := iterate(ctx, handler, b)
 
 	if err != nil {
 		return nil, err
 	}
 
-	defer rows.Close()
+	defer cursor.Close()
 
-	columns, _ := rows.Columns()
+	if cursor.Next() {
+		result, err := cursor.ScanMap()
 
-	values := make([]interface{}, len(columns))
-	scanArgs := make([]interface{}, len(values))
-	for j := range values {
-		scanArgs[j] = &values[j]
-	}
-
-	if rows.Next() {
-		result := make([]interface{}, 1)
-
-		rows.Scan(scanArgs...)
-		for i := range columns {
-			result[i] = values[i]
-		}
-		return result, nil
-	} else {
-		return nil, nil
+		return result, wrapError(b.GetSQL(), err)
 	}
 
+	return nil, wrapError(b.GetSQL(), cursor.Err())
 }
 
-func firstResultType(handler handlerConn, b Builder, d interface{}) error {
-	rows, err := handler.Query(b.GetSQL(), b.GetParameters()...)
+func firstResultArray(ctx context.Context, handler handlerConn, b Builder) ([]interface{}, error) {
+	cursor, err := iterate(ctx, handler, b)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	defer rows.Close()
+	defer cursor.Close()
 
-	_, err = LoadOne(rows, d)
+	if cursor.Next() {
+		result, err := cursor.ScanArray()
 
-	if err != nil {
-		return err
+		return result, wrapError(b.GetSQL(), err)
 	}
 
-	return nil
+	return nil, wrapError(b.GetSQL(), cursor.Err())
+}
+
+func firstResultType(ctx context.Context, handler handlerConn, b Builder, d interface{}) error {
+	return loadType(ctx, handler, b, d, true)
 }
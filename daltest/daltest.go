@@ -0,0 +1,372 @@
+// Package daltest runs the sessions and transactions of dal against expectations instead of a
+// database, so the code using dal can be unit tested.
+//
+//	mock := daltest.New(t)
+//	mock.ExpectQuery("SELECT id, name FROM users WHERE (id = $1)").WithArgs(1).
+//		WillReturnRows([]string{"id", "name"}, []interface{}{1, "ana"})
+//
+//	users, err := NewRepository(mock.Session()).Find(1)
+//
+// The statements must arrive in the order of the expectations, the ones left unmet are reported
+// when the test ends.
+//
+// For the integration tests, Begin gives each test a transaction on a real database that is rolled
+// back when the test ends.
+package daltest
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	dal "github.com/cloudoti/go-dal"
+)
+
+type kind int
+
+const (
+	kindQuery kind = iota
+	kindExec
+	kindBegin
+	kindCommit
+	kindRollback
+)
+
+func (k kind) String() string {
+	return [...]string{"query", "exec", "begin", "commit", "rollback"}[k]
+}
+
+// Argument matches a parameter of a statement, for the values that are not known in advance.
+type Argument interface {
+	Match(v interface{}) bool
+}
+
+type anyArg struct{}
+
+func (anyArg) Match(interface{}) bool {
+	return true
+}
+
+func (anyArg) String() string {
+	return "<any>"
+}
+
+// AnyArg matches any parameter.
+func AnyArg() Argument {
+	return anyArg{}
+}
+
+// Expectation is a statement the code under test must run, and what it receives back.
+type Expectation struct {
+	kind    kind
+	sql     string
+	pattern *regexp.Regexp
+	args    []interface{}
+	hasArgs bool
+
+	columns      []string
+	rows         [][]driver.Value
+	lastInsertID int64
+	rowsAffected int64
+	err          error
+
+	met bool
+}
+
+// WithArgs sets the parameters the statement must receive, as values or Argument.
+func (e *Expectation) WithArgs(args ...interface{}) *Expectation {
+	e.args = args
+	e.hasArgs = true
+	return e
+}
+
+// WillReturnRows sets the rows sent back by a query.
+func (e *Expectation) WillReturnRows(columns []string, rows ...[]interface{}) *Expectation {
+	e.columns = columns
+	for _, r := range rows {
+		values := make([]driver.Value, len(r))
+		for i, v := range r {
+			values[i] = toValue(v)
+		}
+		e.rows = append(e.rows, values)
+	}
+	return e
+}
+
+// WillReturnMaps sets the rows sent back by a query, the columns are the keys of the maps in
+// alphabetical order.
+func (e *Expectation) WillReturnMaps(rows ...map[string]interface{}) *Expectation {
+	keys := make(map[string]bool)
+	for _, r := range rows {
+		for k := range r {
+			keys[k] = true
+		}
+	}
+
+	columns := make([]string, 0, len(keys))
+	for k := range keys {
+		columns = append(columns, k)
+	}
+	sort.Strings(columns)
+
+	values := make([][]interface{}, len(rows))
+	for i, r := range rows {
+		values[i] = make([]interface{}, len(columns))
+		for j, c := range columns {
+			values[i][j] = r[c]
+		}
+	}
+
+	return e.WillReturnRows(columns, values...)
+}
+
+// WillReturnStructs sets the rows sent back by a query from structs, their columns are the ones
+// dal loads into the fields, see dal.StructColumns.
+func (e *Expectation) WillReturnStructs(rows ...interface{}) *Expectation {
+	var columns []string
+	values := make([][]interface{}, len(rows))
+
+	for i, r := range rows {
+		if reflect.Indirect(reflect.ValueOf(r)).Kind() != reflect.Struct {
+			panic(fmt.Sprintf("daltest: WillReturnStructs expects structs, got %T", r))
+		}
+
+		var cols []string
+		cols, values[i] = dal.StructColumns(r)
+
+		if columns == nil {
+			columns = cols
+		}
+	}
+
+	return e.WillReturnRows(columns, values...)
+}
+
+// WillReturnResult sets the outcome of an exec.
+func (e *Expectation) WillReturnResult(lastInsertID, rowsAffected int64) *Expectation {
+	e.lastInsertID = lastInsertID
+	e.rowsAffected = rowsAffected
+	return e
+}
+
+// WillReturnError makes the statement fail with err.
+func (e *Expectation) WillReturnError(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+func (e *Expectation) String() string {
+	s := e.kind.String()
+	if e.pattern != nil {
+		s += " matching " + e.pattern.String()
+	} else if e.sql != "" {
+		s += " " + e.sql
+	}
+	if e.hasArgs {
+		s += fmt.Sprintf(" with args %v", e.args)
+	}
+	return s
+}
+
+// Mock holds the expectations of a test and the connection that checks them.
+type Mock struct {
+	t            testing.TB
+	mu           sync.Mutex
+	expectations []*Expectation
+	conn         *dal.Connection
+}
+
+// New returns a mock whose unmet expectations fail t when the test ends.
+func New(t testing.TB) *Mock {
+	m := &Mock{t: t}
+	m.conn = dal.Wrap(sql.OpenDB(connector{m}))
+
+	t.Cleanup(func() {
+		if err := m.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		m.conn.Close()
+	})
+
+	return m
+}
+
+// Connection returns the connection running the statements against the expectations.
+func (m *Mock) Connection() *dal.Connection {
+	return m.conn
+}
+
+// Session returns a session of the connection of the mock.
+func (m *Mock) Session() *dal.Session {
+	return m.conn.GetSession()
+}
+
+// ExpectQuery expects a query with exactly this SQL.
+func (m *Mock) ExpectQuery(sql string) *Expectation {
+	return m.expect(&Expectation{kind: kindQuery, sql: sql})
+}
+
+// ExpectQueryRegexp expects a query whose SQL matches pattern.
+func (m *Mock) ExpectQueryRegexp(pattern string) *Expectation {
+	return m.expect(&Expectation{kind: kindQuery, pattern: regexp.MustCompile(pattern)})
+}
+
+// ExpectExec expects an exec with exactly this SQL.
+func (m *Mock) ExpectExec(sql string) *Expectation {
+	return m.expect(&Expectation{kind: kindExec, sql: sql})
+}
+
+// ExpectExecRegexp expects an exec whose SQL matches pattern.
+func (m *Mock) ExpectExecRegexp(pattern string) *Expectation {
+	return m.expect(&Expectation{kind: kindExec, pattern: regexp.MustCompile(pattern)})
+}
+
+// ExpectBegin expects a transaction to start.
+func (m *Mock) ExpectBegin() *Expectation {
+	return m.expect(&Expectation{kind: kindBegin})
+}
+
+// ExpectCommit expects a transaction to be committed.
+func (m *Mock) ExpectCommit() *Expectation {
+	return m.expect(&Expectation{kind: kindCommit})
+}
+
+// ExpectRollback expects a transaction to be rolled back.
+func (m *Mock) ExpectRollback() *Expectation {
+	return m.expect(&Expectation{kind: kindRollback})
+}
+
+// ExpectationsWereMet returns an error listing the expectations that were not met.
+func (m *Mock) ExpectationsWereMet() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var unmet []string
+	for _, e := range m.expectations {
+		if !e.met {
+			unmet = append(unmet, "  - "+e.String())
+		}
+	}
+
+	if len(unmet) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("daltest: %d expectations were not met:\n%s", len(unmet), strings.Join(unmet, "\n"))
+}
+
+func (m *Mock) expect(e *Expectation) *Expectation {
+	m.mu.Lock()
+	m.expectations = append(m.expectations, e)
+	m.mu.Unlock()
+	return e
+}
+
+// next checks a statement against the first unmet expectation, reporting the mismatch to the test.
+func (m *Mock) next(k kind, query string, args []driver.NamedValue) (*Expectation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var e *Expectation
+	for _, candidate := range m.expectations {
+		if !candidate.met {
+			e = candidate
+			break
+		}
+	}
+
+	if e == nil {
+		return nil, m.fail("unexpected %s, no expectation left:\n  got:  %s", k, describe(query, args))
+	}
+
+	if e.kind != k {
+		return nil, m.fail("unexpected %s, expected %s:\n  got:  %s", k, e, describe(query, args))
+	}
+
+	if k == kindQuery || k == kindExec {
+		if e.pattern != nil && !e.pattern.MatchString(query) {
+			return nil, m.fail("%s does not match:\n  want: %s\n  got:  %s", k, e.pattern, query)
+		}
+
+		if e.pattern == nil && e.sql != query {
+			return nil, m.fail("%s differs:\n%s", k, diff(e.sql, query))
+		}
+
+		if e.hasArgs {
+			if err := matchArgs(e.args, args); err != "" {
+				return nil, m.fail("%s %s: %s", k, query, err)
+			}
+		}
+	}
+
+	e.met = true
+
+	return e, e.err
+}
+
+func (m *Mock) fail(format string, args ...interface{}) error {
+	err := fmt.Errorf("daltest: "+format, args...)
+	m.t.Error(err)
+	return err
+}
+
+func matchArgs(want []interface{}, got []driver.NamedValue) string {
+	if len(want) != len(got) {
+		return fmt.Sprintf("expected %d args %v, got %d %v", len(want), want, len(got), values(got))
+	}
+
+	for i, w := range want {
+		g := got[i].Value
+
+		if a, ok := w.(Argument); ok {
+			if !a.Match(g) {
+				return fmt.Sprintf("arg %d does not match: want %v, got %#v", i, a, g)
+			}
+			continue
+		}
+
+		if !reflect.DeepEqual(toValue(w), toValue(g)) {
+			return fmt.Sprintf("arg %d differs: want %#v, got %#v", i, w, g)
+		}
+	}
+
+	return ""
+}
+
+// toValue converts v as the driver would receive it, so 1 and int64(1) are the same argument.
+func toValue(v interface{}) driver.Value {
+	if dv, err := driver.DefaultParameterConverter.ConvertValue(v); err == nil {
+		return dv
+	}
+	return v
+}
+
+func values(args []driver.NamedValue) []interface{} {
+	v := make([]interface{}, len(args))
+	for i, a := range args {
+		v[i] = a.Value
+	}
+	return v
+}
+
+func describe(query string, args []driver.NamedValue) string {
+	if len(args) == 0 {
+		return query
+	}
+	return fmt.Sprintf("%s with args %v", query, values(args))
+}
+
+// diff shows both statements with a marker under the first character that differs.
+func diff(want, got string) string {
+	i := 0
+	for i < len(want) && i < len(got) && want[i] == got[i] {
+		i++
+	}
+	return fmt.Sprintf("  want: %s\n  got:  %s\n        %s^", want, got, strings.Repeat(" ", i))
+}
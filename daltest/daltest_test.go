@@ -0,0 +1,196 @@
+package daltest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	dal "github.com/cloudoti/go-dal"
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder keeps the failures instead of failing the test, to check what the mock reports.
+type recorder struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (r *recorder) Error(args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprint(args...))
+}
+
+func (r *recorder) Cleanup(f func()) {
+	r.cleanups = append(r.cleanups, f)
+}
+
+func (r *recorder) end() {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+}
+
+type user struct {
+	ID        int64 `db:"id"`
+	FirstName string
+	Email     string `db:"email,sensitive"`
+}
+
+func TestSession(t *testing.T) {
+	mock := New(t)
+
+	mock.ExpectQuery("SELECT id, first_name FROM users WHERE (id = $1)").WithArgs(1).
+		WillReturnRows([]string{"id", "first_name"}, []interface{}{1, "ana"})
+	mock.ExpectQueryRegexp(`^SELECT \* FROM users`).
+		WillReturnStructs(user{ID: 1, FirstName: "ana", Email: "ana@test"}, user{ID: 2, FirstName: "bea"})
+	mock.ExpectQuery("SELECT COUNT(1) FROM users").WillReturnMaps(map[string]interface{}{"count": 2})
+
+	sess := mock.Session()
+
+	b := dal.NewBuilder()
+	b.Select("id", "first_name").From("users").Where("id = ?").SetParameter(0, 1)
+	b.Build()
+
+	row, err := sess.FirstResult(*b)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"id": int64(1), "first_name": "ana"}, row)
+
+	b = dal.NewBuilder()
+	b.Select("*").From("users")
+	b.Build()
+
+	var users []user
+	assert.NoError(t, sess.QueryType(*b, &users))
+	assert.Equal(t, []user{{ID: 1, FirstName: "ana", Email: "ana@test"}, {ID: 2, FirstName: "bea"}}, users)
+
+	b = dal.NewBuilder()
+	b.SQL("SELECT COUNT(1) FROM users")
+	b.Build()
+
+	var count int
+	assert.NoError(t, sess.Scan(*b, &count))
+	assert.Equal(t, 2, count)
+}
+
+type base struct {
+	ID int64 `db:"name=id"`
+}
+
+type account struct {
+	base
+	Owner  user `db:"-"`
+	Status string
+}
+
+func TestWillReturnStructsMapping(t *testing.T) {
+	mock := New(t)
+
+	// the columns are the ones QueryType loads: name= tags and embedded structs flattened
+	e := mock.ExpectQuery("SELECT * FROM accounts").WillReturnStructs(account{base: base{ID: 3}, Status: "open"})
+	assert.Equal(t, []string{"id", "status"}, e.columns)
+
+	b := dal.NewBuilder()
+	b.Select("*").From("accounts")
+	b.Build()
+
+	var accounts []account
+	assert.NoError(t, mock.Session().QueryType(*b, &accounts))
+	assert.Equal(t, []account{{base: base{ID: 3}, Status: "open"}}, accounts)
+}
+
+func TestTransaction(t *testing.T) {
+	mock := New(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users SET name = $1 WHERE (id = $2)").WithArgs("ana", AnyArg()).WillReturnResult(0, 1)
+	mock.ExpectExecRegexp(`^DELETE FROM users\s+WHERE`).WillReturnError(errors.New("locked"))
+	mock.ExpectRollback()
+
+	err := mock.Connection().RunInTransaction(context.Background(), nil, func(tx *dal.Transaction) error {
+		b := dal.NewBuilder()
+		b.Update("users").Set("name", "?").Where("id = ?").SetParameter(0, "ana").SetParameter(1, 7)
+		b.Build()
+
+		if err := tx.Exec(*b); err != nil {
+			return err
+		}
+
+		b = dal.NewBuilder()
+		b.Delete("users").Where("id = ?").SetParameter(0, 7)
+		b.Build()
+
+		return tx.Exec(*b)
+	})
+
+	assert.EqualError(t, errors.Unwrap(err), "locked")
+}
+
+func TestUnexpectedStatements(t *testing.T) {
+	r := &recorder{}
+	mock := New(r)
+
+	mock.ExpectQuery("SELECT id FROM users WHERE (id = $1)").WithArgs(2)
+	mock.ExpectCommit()
+
+	b := dal.NewBuilder()
+	b.Select("id").From("user").Where("id = ?").SetParameter(0, 2)
+	b.Build()
+
+	_, err := mock.Session().Query(*b)
+	assert.Error(t, err)
+
+	b = dal.NewBuilder()
+	b.Select("id").From("users").Where("id = ?").SetParameter(0, 3)
+	b.Build()
+
+	_, err = mock.Session().Query(*b)
+	assert.Error(t, err)
+
+	r.end()
+
+	assert.Len(t, r.errors, 3)
+	assert.Equal(t, strings.Join([]string{
+		"daltest: query differs:",
+		"  want: SELECT id FROM users WHERE (id = $1)",
+		"  got:  SELECT id FROM user WHERE (id = $1)",
+		"                           ^",
+	}, "\n"), r.errors[0])
+	assert.Equal(t, "daltest: query SELECT id FROM users WHERE (id = $1): arg 0 differs: want 2, got 3", r.errors[1])
+	assert.Equal(t, strings.Join([]string{
+		"daltest: 2 expectations were not met:",
+		"  - query SELECT id FROM users WHERE (id = $1) with args [2]",
+		"  - commit",
+	}, "\n"), r.errors[2])
+}
+
+func TestBegin(t *testing.T) {
+	mock := New(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExecRegexp(`^CREATE SCHEMA "dal_test_testbegin_per_test_[0-9a-f]{8}"$`)
+	mock.ExpectExecRegexp(`^SET LOCAL search_path TO "dal_test_testbegin_per_test_[0-9a-f]{8}", public$`)
+	mock.ExpectExec("DELETE FROM users ").WillReturnResult(0, 3)
+	mock.ExpectRollback()
+
+	t.Run("per test", func(t *testing.T) {
+		tx := Begin(t, mock.Connection(), WithSchema())
+
+		b := dal.NewBuilder()
+		b.Delete("users")
+		b.Build()
+
+		assert.NoError(t, tx.Exec(*b))
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	t.Run("committed", func(t *testing.T) {
+		tx := Begin(t, mock.Connection())
+		assert.NoError(t, tx.Commit())
+	})
+}
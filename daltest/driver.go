@@ -0,0 +1,160 @@
+package daltest
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+)
+
+// connector opens the connections of a Mock, every statement they receive is checked by the mock.
+type connector struct {
+	mock *Mock
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{mock: c.mock}, nil
+}
+
+func (c connector) Driver() driver.Driver {
+	return mockDriver{}
+}
+
+type mockDriver struct{}
+
+func (mockDriver) Open(string) (driver.Conn, error) {
+	return nil, driver.ErrBadConn
+}
+
+type conn struct {
+	mock *Mock
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{conn: c, query: query}, nil
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if _, err := c.mock.next(kindBegin, "BEGIN", nil); err != nil {
+		return nil, err
+	}
+	return tx{conn: c}, nil
+}
+
+// CheckNamedValue passes the parameters untouched, so the expectations see what the builder set.
+func (c *conn) CheckNamedValue(*driver.NamedValue) error {
+	return nil
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	e, err := c.mock.next(kindQuery, query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &rows{columns: e.columns, values: e.rows}, nil
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, err := c.mock.next(kindExec, query, args)
+	if err != nil {
+		return nil, err
+	}
+	return result{lastInsertID: e.lastInsertID, rowsAffected: e.rowsAffected}, nil
+}
+
+type tx struct {
+	conn *conn
+}
+
+func (t tx) Commit() error {
+	_, err := t.conn.mock.next(kindCommit, "COMMIT", nil)
+	return err
+}
+
+func (t tx) Rollback() error {
+	_, err := t.conn.mock.next(kindRollback, "ROLLBACK", nil)
+	return err
+}
+
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+func (s *stmt) Close() error {
+	return nil
+}
+
+func (s *stmt) NumInput() int {
+	return -1
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), named(args))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), named(args))
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.conn.ExecContext(ctx, s.query, args)
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.QueryContext(ctx, s.query, args)
+}
+
+func (s *stmt) CheckNamedValue(*driver.NamedValue) error {
+	return nil
+}
+
+func named(args []driver.Value) []driver.NamedValue {
+	n := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		n[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return n
+}
+
+type result struct {
+	lastInsertID, rowsAffected int64
+}
+
+func (r result) LastInsertId() (int64, error) {
+	return r.lastInsertID, nil
+}
+
+func (r result) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
+
+type rows struct {
+	columns []string
+	values  [][]driver.Value
+	pos     int
+}
+
+func (r *rows) Columns() []string {
+	return r.columns
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.pos])
+	r.pos++
+	return nil
+}
@@ -0,0 +1,101 @@
+package daltest
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	dal "github.com/cloudoti/go-dal"
+)
+
+// Option configures the transaction returned by Begin.
+type Option func(*options)
+
+type options struct {
+	tx     *dal.TxOptions
+	schema bool
+}
+
+// WithTxOptions starts the transaction with opts.
+func WithTxOptions(opts dal.TxOptions) Option {
+	return func(o *options) {
+		o.tx = &opts
+	}
+}
+
+// WithSchema creates a schema for the test and puts it first in the search_path of the transaction,
+// so the tables it creates do not collide with the ones of the tests running in parallel.
+func WithSchema() Option {
+	return func(o *options) {
+		o.schema = true
+	}
+}
+
+var schemaChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Begin starts a transaction on conn that is rolled back when the test ends, leaving the database as
+// it was. The transaction implements dal.ISession, so it can be given to the code under test in place
+// of a session.
+func Begin(t testing.TB, conn *dal.Connection, opts ...Option) *dal.Transaction {
+	t.Helper()
+
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	tx, err := conn.GetTransaction(txOptions(o.tx)...)
+
+	if err != nil {
+		t.Fatalf("daltest: begin: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			t.Errorf("daltest: rollback: %v", err)
+		}
+	})
+
+	if o.schema {
+		schema := SchemaName(t)
+
+		for _, q := range []string{
+			"CREATE SCHEMA " + conn.Dialect().QuoteIdent(schema),
+			"SET LOCAL search_path TO " + conn.Dialect().QuoteIdent(schema) + ", public",
+		} {
+			b := conn.NewBuilder()
+			b.SQL(q)
+			b.RawBuild()
+
+			if err := tx.Exec(*b); err != nil {
+				t.Fatalf("daltest: %v", err)
+			}
+		}
+	}
+
+	return tx
+}
+
+// SchemaName returns a schema name unique to this run of the test.
+func SchemaName(t testing.TB) string {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+
+	name := strings.Trim(schemaChars.ReplaceAllString(strings.ToLower(t.Name()), "_"), "_")
+	if len(name) > 40 {
+		name = name[:40]
+	}
+
+	return "dal_test_" + name + "_" + hex.EncodeToString(suffix)
+}
+
+func txOptions(opts *dal.TxOptions) []dal.TxOptions {
+	if opts == nil {
+		return nil
+	}
+	return []dal.TxOptions{*opts}
+}
@@ -8,12 +8,14 @@ type DeleteBuilder struct {
 	b *Builder
 }
 
-func (b *DeleteBuilder) Where(condition string) *DeleteBuilder {
-	return b.addWhere("AND", condition)
+//Where - adds a condition given as a string or a Cond, joined with AND
+func (b *DeleteBuilder) Where(condition interface{}) *DeleteBuilder {
+	return b.addWhere("AND", b.b.condition(condition))
 }
 
-func (b *DeleteBuilder) OrWhere(condition string) *DeleteBuilder {
-	return b.addWhere("OR", condition)
+//OrWhere - adds a condition given as a string or a Cond, joined with OR
+func (b *DeleteBuilder) OrWhere(condition interface{}) *DeleteBuilder {
+	return b.addWhere("OR", b.b.condition(condition))
 }
 
 func (b *DeleteBuilder) Build() {
@@ -0,0 +1,238 @@
+package dal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dialect renders the parts of a statement that differ between databases.
+type Dialect interface {
+	// Name identifies the database, it is reported as the db.system attribute of the spans.
+	Name() string
+	// Placeholder returns the placeholder of the parameter n, starting at 1.
+	Placeholder(n int) string
+	// QuoteIdent quotes an identifier, escaping the quotes it contains.
+	QuoteIdent(name string) string
+	// LimitOffset returns the clause keeping up to limit rows after skipping offset, a limit of 0 means no limit.
+	LimitOffset(limit, offset int64) string
+	// Returning renders the columns sent back by an INSERT, output goes before VALUES and suffix at the end.
+	// ok is false when the database only reports the last insert id through sql.Result.
+	Returning(columns []string) (output, suffix string, ok bool)
+	// Bool returns a literal usable as a condition that is always v.
+	Bool(v bool) string
+}
+
+var (
+	// Postgres is the default dialect of the builders.
+	Postgres Dialect = postgresDialect{}
+	MySQL    Dialect = mysqlDialect{}
+	SQLite   Dialect = sqliteDialect{}
+	// SQLServer requires an ORDER BY on the queries with FirstResult or MaxResult.
+	SQLServer Dialect = sqlServerDialect{}
+)
+
+// dialectForDriver returns the dialect of a driver, given by its registered name or by the type of
+// its driver.Driver, Postgres when it is not known.
+func dialectForDriver(driver string) Dialect {
+	driver = strings.ToLower(driver)
+
+	switch {
+	case strings.Contains(driver, "mysql"):
+		return MySQL
+	case strings.Contains(driver, "sqlite"):
+		return SQLite
+	case strings.Contains(driver, "sqlserver") || strings.Contains(driver, "mssql"):
+		return SQLServer
+	}
+
+	return Postgres
+}
+
+// SetDialect sets the dialect of the builders returned by NewBuilder.
+func (c *Connection) SetDialect(d Dialect) {
+	c.dialect = d
+}
+
+// Dialect returns the dialect of the connection, chosen from its driver unless set with SetDialect.
+func (c *Connection) Dialect() Dialect {
+	if c.dialect == nil {
+		return Postgres
+	}
+	return c.dialect
+}
+
+// NewBuilder returns a builder rendering its statements with the dialect of the connection.
+func (c *Connection) NewBuilder() *Builder {
+	return NewBuilder().Dialect(c.Dialect())
+}
+
+// Dialect sets how the statement is rendered, Postgres by default.
+func (b *Builder) Dialect(d Dialect) *Builder {
+	b.dialect = d
+	return b
+}
+
+func (b *Builder) getDialect() Dialect {
+	if b.dialect == nil {
+		return Postgres
+	}
+	return b.dialect
+}
+
+// checkDialect rejects a statement rendered for another database than the one of the connection, such
+// as one of the package NewBuilder, which uses the Postgres placeholders, run on MySQL.
+func checkDialect(handler handlerConn, b Builder) error {
+	h, ok := handler.(hookedConn)
+	if !ok {
+		return nil
+	}
+
+	if built, conn := b.getDialect().Name(), h.conn.Dialect().Name(); built != conn {
+		return fmt.Errorf("dal: the statement is rendered for %s but the connection is %s, use Connection.NewBuilder or Builder.Dialect", built, conn)
+	}
+
+	return nil
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string {
+	return "postgresql"
+}
+
+func (postgresDialect) Placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+func (postgresDialect) QuoteIdent(name string) string {
+	return quoteIdentifier(name)
+}
+
+func (postgresDialect) LimitOffset(limit, offset int64) string {
+	return limitOffset(limit, offset)
+}
+
+func (postgresDialect) Returning(columns []string) (string, string, bool) {
+	return "", " RETURNING " + strings.Join(columns, ", "), true
+}
+
+func (postgresDialect) Bool(v bool) string {
+	if v {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string {
+	return "mysql"
+}
+
+func (mysqlDialect) Placeholder(int) string {
+	return "?"
+}
+
+func (mysqlDialect) QuoteIdent(name string) string {
+	return "`" + strings.Replace(name, "`", "``", -1) + "`"
+}
+
+func (mysqlDialect) LimitOffset(limit, offset int64) string {
+	if limit == 0 && offset > 0 {
+		// MySQL has no OFFSET without LIMIT
+		return " LIMIT 18446744073709551615 OFFSET " + strconv.FormatInt(offset, 10)
+	}
+	return limitOffset(limit, offset)
+}
+
+func (mysqlDialect) Returning([]string) (string, string, bool) {
+	return "", "", false
+}
+
+func (mysqlDialect) Bool(v bool) string {
+	if v {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string {
+	return "sqlite"
+}
+
+func (sqliteDialect) Placeholder(int) string {
+	return "?"
+}
+
+func (sqliteDialect) QuoteIdent(name string) string {
+	return quoteIdentifier(name)
+}
+
+func (sqliteDialect) LimitOffset(limit, offset int64) string {
+	if limit == 0 && offset > 0 {
+		return " LIMIT -1 OFFSET " + strconv.FormatInt(offset, 10)
+	}
+	return limitOffset(limit, offset)
+}
+
+func (sqliteDialect) Returning(columns []string) (string, string, bool) {
+	return "", " RETURNING " + strings.Join(columns, ", "), true
+}
+
+func (sqliteDialect) Bool(v bool) string {
+	if v {
+		return "1"
+	}
+	return "0"
+}
+
+type sqlServerDialect struct{}
+
+func (sqlServerDialect) Name() string {
+	return "mssql"
+}
+
+func (sqlServerDialect) Placeholder(n int) string {
+	return "@p" + strconv.Itoa(n)
+}
+
+func (sqlServerDialect) QuoteIdent(name string) string {
+	return "[" + strings.Replace(name, "]", "]]", -1) + "]"
+}
+
+func (sqlServerDialect) LimitOffset(limit, offset int64) string {
+	q := fmt.Sprintf(" OFFSET %d ROWS", offset)
+	if limit > 0 {
+		q += fmt.Sprintf(" FETCH NEXT %d ROWS ONLY", limit)
+	}
+	return q
+}
+
+func (sqlServerDialect) Returning(columns []string) (string, string, bool) {
+	output := make([]string, len(columns))
+	for i, c := range columns {
+		output[i] = "INSERTED." + c
+	}
+	return " OUTPUT " + strings.Join(output, ", "), "", true
+}
+
+func (sqlServerDialect) Bool(v bool) string {
+	if v {
+		return "1=1"
+	}
+	return "1=0"
+}
+
+func limitOffset(limit, offset int64) string {
+	q := ""
+	if limit > 0 {
+		q += " LIMIT " + strconv.FormatInt(limit, 10)
+	}
+	if offset > 0 {
+		q += " OFFSET " + strconv.FormatInt(offset, 10)
+	}
+	return q
+}
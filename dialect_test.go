@@ -0,0 +1,140 @@
+package dal
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDialects(t *testing.T) {
+	tests := []struct {
+		dialect        Dialect
+		selectSQL      string
+		insertSQL      string
+		truth, falsity string
+	}{
+		{Postgres, "SELECT id FROM test WHERE (id = $1) LIMIT 10 OFFSET 20", "INSERT INTO test(name) VALUES ($1) RETURNING id", "TRUE", "FALSE"},
+		{MySQL, "SELECT id FROM test WHERE (id = ?) LIMIT 10 OFFSET 20", "INSERT INTO test(name) VALUES (?)", "TRUE", "FALSE"},
+		{SQLite, "SELECT id FROM test WHERE (id = ?) LIMIT 10 OFFSET 20", "INSERT INTO test(name) VALUES (?) RETURNING id", "1", "0"},
+		{SQLServer, "SELECT id FROM test WHERE (id = @p1) OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY", "INSERT INTO test(name) OUTPUT INSERTED.id VALUES (@p1)", "1=1", "1=0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect.Name(), func(t *testing.T) {
+			b := NewBuilder().Dialect(tt.dialect)
+			b.Select("id").From("test").Where("id = ?").FirstResult(21).MaxResult(10).SetParameter(0, 1)
+			b.Build()
+			assert.Equal(t, tt.selectSQL, b.GetSQL())
+
+			b = NewBuilder().Dialect(tt.dialect)
+			b.Insert("test").Column("name", "?").LastInsertId().SetParameter(0, "a")
+			b.Build()
+			assert.Equal(t, tt.insertSQL, b.GetSQL())
+
+			assert.Equal(t, tt.truth, tt.dialect.Bool(true))
+			assert.Equal(t, tt.falsity, tt.dialect.Bool(false))
+		})
+	}
+
+	assert.Equal(t, `"a""b"`, Postgres.QuoteIdent(`a"b`))
+	assert.Equal(t, "`a``b`", MySQL.QuoteIdent("a`b"))
+	assert.Equal(t, "[a]]b]", SQLServer.QuoteIdent("a]b"))
+	assert.Equal(t, " LIMIT -1 OFFSET 5", SQLite.LimitOffset(0, 5))
+}
+
+func TestDialectForDriver(t *testing.T) {
+	assert.Equal(t, Postgres, dialectForDriver("postgres"))
+	assert.Equal(t, Postgres, dialectForDriver("cloudsqlpostgres"))
+	assert.Equal(t, MySQL, dialectForDriver("*mysql.MySQLDriver"))
+	assert.Equal(t, SQLite, dialectForDriver("sqlite3"))
+	assert.Equal(t, SQLServer, dialectForDriver("sqlserver"))
+
+	conn := newFakeConnection(&fakeDB{})
+	assert.Equal(t, Postgres, conn.Dialect())
+
+	conn.SetDialect(MySQL)
+	b := conn.NewBuilder()
+	b.Select("id").From("test").Where("id = ?").SetParameter(0, 1)
+	b.Build()
+	assert.Equal(t, "SELECT id FROM test WHERE (id = ?)", b.GetSQL())
+}
+
+type lastInsertResult int64
+
+func (r lastInsertResult) LastInsertId() (int64, error) { return int64(r), nil }
+func (r lastInsertResult) RowsAffected() (int64, error) { return 1, nil }
+
+func TestLastInsertIdWithoutReturning(t *testing.T) {
+	f := &fakeDB{exec: func(q string, args []driver.Value) (driver.Result, error) {
+		return lastInsertResult(42), nil
+	}}
+
+	conn := newFakeConnection(f)
+	conn.SetDialect(MySQL)
+	sess := conn.GetSession()
+
+	b := conn.NewBuilder()
+	b.Insert("test").Column("name", "?").LastInsertId().SetParameter(0, "a")
+	b.Build()
+
+	var id int
+	assert.NoError(t, sess.Insert(*b, &id))
+	assert.Equal(t, 42, id)
+
+	res, err := sess.ExecResult(*b)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), res.LastInsertId())
+	assert.Equal(t, int64(1), res.RowsAffected())
+
+	assert.Equal(t, []string{"INSERT INTO test(name) VALUES (?)", "INSERT INTO test(name) VALUES (?)"}, f.Statements())
+}
+
+func TestDialectOfTheConnection(t *testing.T) {
+	f := &fakeDB{}
+	conn := newFakeConnection(f)
+	conn.SetDialect(MySQL)
+
+	b := NewBuilder()
+	b.Delete("test").Where("id = ?").SetParameter(0, 1)
+	b.Build()
+
+	assert.EqualError(t, conn.GetSession().Exec(*b), "dal: the statement is rendered for postgresql but the connection is mysql, use Connection.NewBuilder or Builder.Dialect")
+
+	tx, err := conn.GetTransaction()
+	assert.NoError(t, err)
+
+	child, err := tx.Begin()
+	assert.NoError(t, err)
+
+	b = conn.NewBuilder()
+	b.Timeout(time.Second).Delete("test").Where("id = ?").SetParameter(0, 1)
+	b.Build()
+
+	assert.NoError(t, child.Exec(*b))
+	assert.NoError(t, child.Rollback())
+	assert.NoError(t, tx.Commit())
+
+	conn.SetDialect(SQLServer)
+
+	tx, err = conn.GetTransaction()
+	assert.NoError(t, err)
+
+	child, err = tx.Begin()
+	assert.NoError(t, err)
+	assert.NoError(t, child.Rollback())
+	assert.NoError(t, tx.Commit())
+
+	assert.Equal(t, []string{
+		"BEGIN",
+		"SAVEPOINT `dal_savepoint_1`",
+		"DELETE FROM test  WHERE (id = ?)",
+		"ROLLBACK TO SAVEPOINT `dal_savepoint_1`",
+		"RELEASE SAVEPOINT `dal_savepoint_1`",
+		"COMMIT",
+		"BEGIN",
+		"SAVE TRANSACTION [dal_savepoint_1]",
+		"ROLLBACK TRANSACTION [dal_savepoint_1]",
+		"COMMIT"}, f.Statements())
+}
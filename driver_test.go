@@ -0,0 +1,226 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fakeDB is an in-process driver used to test the session readers without a running database.
+type fakeDB struct {
+	sync.Mutex
+	query      func(query string, args []driver.Value) (driver.Rows, error)
+	exec       func(query string, args []driver.Value) (driver.Result, error)
+	statements []string
+	// delay makes every statement wait, or fail when its context is done first
+	delay time.Duration
+}
+
+func newFakeConnection(f *fakeDB) *Connection {
+	return Wrap(sql.OpenDB(fakeConnector{f}))
+}
+
+func (f *fakeDB) log(query string) {
+	f.Lock()
+	f.statements = append(f.statements, query)
+	f.Unlock()
+}
+
+func (f *fakeDB) Statements() []string {
+	f.Lock()
+	defer f.Unlock()
+	return append([]string(nil), f.statements...)
+}
+
+type fakeConnector struct {
+	db *fakeDB
+}
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return &fakeConn{db: c.db}, nil
+}
+
+func (c fakeConnector) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+// fakeDriver is registered as dalfake, it opens the fakeDB registered for the DSN.
+type fakeDriver struct{}
+
+var fakeDSNs = struct {
+	sync.Mutex
+	dbs map[string]*fakeDB
+}{dbs: make(map[string]*fakeDB)}
+
+func init() {
+	sql.Register("dalfake", fakeDriver{})
+}
+
+func registerFakeDB(dsn string, f *fakeDB) {
+	fakeDSNs.Lock()
+	fakeDSNs.dbs[dsn] = f
+	fakeDSNs.Unlock()
+}
+
+func (fakeDriver) Open(dsn string) (driver.Conn, error) {
+	fakeDSNs.Lock()
+	f, ok := fakeDSNs.dbs[dsn]
+	fakeDSNs.Unlock()
+
+	if !ok {
+		return nil, driver.ErrBadConn
+	}
+	return &fakeConn{db: f}, nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	begin := "BEGIN"
+	if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) {
+		begin += " ISOLATION LEVEL " + strings.ToUpper(sql.IsolationLevel(opts.Isolation).String())
+	}
+	if opts.ReadOnly {
+		begin += " READ ONLY"
+	}
+	c.db.log(begin)
+	return fakeTx{db: c.db}, nil
+}
+
+func (c *fakeConn) run(query string, args []driver.Value) (driver.Rows, error) {
+	c.db.log(query)
+	if c.db.query == nil {
+		return &fakeRows{}, nil
+	}
+	return c.db.query(query, args)
+}
+
+func (c *fakeConn) runExec(query string, args []driver.Value) (driver.Result, error) {
+	c.db.log(query)
+	if c.db.exec == nil {
+		return driver.RowsAffected(0), nil
+	}
+	return c.db.exec(query, args)
+}
+
+type fakeTx struct {
+	db *fakeDB
+}
+
+func (t fakeTx) Commit() error {
+	t.db.log("COMMIT")
+	return nil
+}
+
+func (t fakeTx) Rollback() error {
+	t.db.log("ROLLBACK")
+	return nil
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) wait(ctx context.Context) error {
+	if s.conn.db.delay == 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(s.conn.db.delay):
+		return nil
+	}
+}
+
+func (s *fakeStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if err := s.wait(ctx); err != nil {
+		return nil, err
+	}
+	return s.Exec(values(args))
+}
+
+func (s *fakeStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if err := s.wait(ctx); err != nil {
+		return nil, err
+	}
+	return s.Query(values(args))
+}
+
+func values(args []driver.NamedValue) []driver.Value {
+	v := make([]driver.Value, len(args))
+	for i, a := range args {
+		v[i] = a.Value
+	}
+	return v
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.runExec(s.query, args)
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.run(s.query, args)
+}
+
+// fakeRows returns values row by row and fails with err once errAt rows were read, when err is set.
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+	err     error
+	errAt   int
+	pos     int
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.err != nil && r.pos == r.errAt {
+		return r.err
+	}
+	if r.pos >= len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.pos])
+	r.pos++
+	return nil
+}
+
+func fakeRowsResult(columns []string, values ...[]driver.Value) func(string, []driver.Value) (driver.Rows, error) {
+	return func(string, []driver.Value) (driver.Rows, error) {
+		return &fakeRows{columns: columns, values: values}, nil
+	}
+}
@@ -0,0 +1,38 @@
+package dal
+
+import "errors"
+
+// ErrReadOnlyTransaction is returned when a write builder is executed inside a READ ONLY transaction.
+var ErrReadOnlyTransaction = errors.New("dal: write statement in a read only transaction")
+
+// QueryError is returned when the database fails to run a statement or to read its result.
+type QueryError struct {
+	SQL string
+	Err error
+}
+
+func (e *QueryError) Error() string {
+	return "dal: " + e.Err.Error() + " [" + e.SQL + "]"
+}
+
+// Unwrap returns the driver error.
+func (e *QueryError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the statement ran out of time when target is ErrTimeout.
+func (e *QueryError) Is(target error) bool {
+	return target == ErrTimeout && isTimeout(e.Err)
+}
+
+func wrapError(sql string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := err.(*QueryError); ok {
+		return err
+	}
+
+	return &QueryError{SQL: sql, Err: err}
+}
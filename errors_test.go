@@ -0,0 +1,175 @@
+package dal
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errNetwork = errors.New("connection reset by peer")
+
+// brokenStream returns one row and then fails as a dropped connection would.
+func brokenStream(string, []driver.Value) (driver.Rows, error) {
+	return &fakeRows{columns: []string{"id", "name"},
+		values: [][]driver.Value{{int64(1), "daniel"}, {int64(2), "johan"}},
+		err:    errNetwork, errAt: 1}, nil
+}
+
+func failedQuery(string, []driver.Value) (driver.Rows, error) {
+	return nil, errNetwork
+}
+
+func assertQueryError(t *testing.T, err error, sql string) {
+	if assert.Error(t, err) {
+		assert.True(t, errors.Is(err, errNetwork), "unexpected error %v", err)
+
+		var qErr *QueryError
+		if assert.True(t, errors.As(err, &qErr)) {
+			assert.Equal(t, sql, qErr.SQL)
+		}
+	}
+}
+
+func readers(sess *Session, b Builder, sb SelectBuilder) map[string]func() error {
+	type row struct {
+		Id   int64
+		Name string
+	}
+
+	return map[string]func() error{
+		"Scan": func() error {
+			var id int64
+			return sess.Scan(b, &id)
+		},
+		"Query": func() error {
+			_, err := sess.Query(b)
+			return err
+		},
+		"QueryArray": func() error {
+			_, err := sess.QueryArray(b)
+			return err
+		},
+		"QueryType": func() error {
+			var o []row
+			return sess.QueryType(b, &o)
+		},
+		"FirstResult": func() error {
+			_, err := sess.FirstResult(b)
+			return err
+		},
+		"FirstResultArray": func() error {
+			_, err := sess.FirstResultArray(b)
+			return err
+		},
+		"FirstResultType": func() error {
+			var o row
+			return sess.FirstResultType(b, &o)
+		},
+		"CountQuery": func() error {
+			_, _, err := sess.CountQuery(sb)
+			return err
+		},
+		"CountQueryArray": func() error {
+			_, _, err := sess.CountQueryArray(sb)
+			return err
+		},
+		"CountQueryType": func() error {
+			var o []row
+			_, err := sess.CountQueryType(sb, &o)
+			return err
+		},
+		"Exec": func() error {
+			return sess.Exec(b)
+		},
+	}
+}
+
+func TestReadersQueryError(t *testing.T) {
+	f := &fakeDB{query: failedQuery, exec: func(string, []driver.Value) (driver.Result, error) {
+		return nil, errNetwork
+	}}
+
+	b := NewBuilder()
+	sb := b.Select("id", "name").From("test")
+	b.Build()
+
+	for name, read := range readers(newFakeConnection(f).GetSession(), *b, *sb) {
+		t.Run(name, func(t *testing.T) {
+			sql := b.GetSQL()
+			if strings.HasPrefix(name, "Count") {
+				sql = sb.GetCountSQL()
+			}
+			assertQueryError(t, read(), sql)
+		})
+	}
+}
+
+func TestReadersStreamError(t *testing.T) {
+	f := &fakeDB{query: func(q string, args []driver.Value) (driver.Rows, error) {
+		if strings.Contains(q, "COUNT(1)") {
+			return fakeRowsResult([]string{"count"}, []driver.Value{int64(2)})(q, args)
+		}
+		return brokenStream(q, args)
+	}}
+
+	b := NewBuilder()
+	sb := b.Select("id", "name").From("test")
+	b.Build()
+
+	for name, read := range readers(newFakeConnection(f).GetSession(), *b, *sb) {
+		if strings.HasPrefix(name, "First") || name == "Scan" || name == "Exec" {
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			assertQueryError(t, read(), b.GetSQL())
+		})
+	}
+}
+
+func TestReadersScanError(t *testing.T) {
+	f := &fakeDB{query: fakeRowsResult([]string{"id", "name"}, []driver.Value{"not a number", "daniel"})}
+
+	sess := newFakeConnection(f).GetSession()
+
+	b := NewBuilder()
+	b.Select("id", "name").From("test").Build()
+
+	var id int64
+	var name string
+	err := sess.Scan(*b, &id, &name)
+
+	var qErr *QueryError
+	assert.True(t, errors.As(err, &qErr))
+
+	var o struct {
+		Id   int64
+		Name string
+	}
+	err = sess.FirstResultType(*b, &o)
+
+	assert.True(t, errors.As(err, &qErr))
+}
+
+func TestCountQueryArrayTotal(t *testing.T) {
+	f := &fakeDB{query: func(q string, args []driver.Value) (driver.Rows, error) {
+		if strings.Contains(q, "COUNT(1)") {
+			return fakeRowsResult([]string{"count"}, []driver.Value{int64(25)})(q, args)
+		}
+		return fakeRowsResult([]string{"id", "name"},
+			[]driver.Value{int64(1), "daniel"},
+			[]driver.Value{int64(2), "johan"})(q, args)
+	}}
+
+	b := NewBuilder()
+	sb := b.Select("id", "name").From("test").MaxResult(2)
+	b.Build()
+
+	result, total, err := newFakeConnection(f).GetSession().CountQueryArray(*sb)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(25), total)
+	assert.Equal(t, [][]interface{}{{int64(1), "daniel"}, {int64(2), "johan"}}, result)
+}
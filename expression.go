@@ -1,57 +1,239 @@
 package dal
 
-import "strings"
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
 
 func As(name, alias string) string {
 	return name + " " + alias
 }
 
-func And(exp ...string) (and string) {
-	if len(exp) > 1 {
-		and += "("
+// Cond is a condition that carries its values, the builders bind them to placeholders numbered at Build.
+// It is accepted by Where, OrWhere and Having along with the plain string conditions.
+type Cond interface {
+	render(b *Builder) string
+}
+
+// condMark delimits the index of a value bound by a Cond until Build replaces it by a placeholder.
+const condMark = "\x01"
+
+type compare struct {
+	column, op string
+	value      interface{}
+}
+
+func (c compare) render(b *Builder) string {
+	if c.value == nil {
+		switch c.op {
+		case "=":
+			return c.column + " IS NULL"
+		case "!=":
+			return c.column + " IS NOT NULL"
+		}
 	}
+	return c.column + " " + c.op + " " + b.bind(c.value)
+}
 
-	and += strings.Join(exp, " AND ")
+type in struct {
+	column string
+	values interface{}
+	not    bool
+}
 
-	if len(exp) > 1 {
-		and += ")"
+func (c in) render(b *Builder) string {
+	if values, ok := expandSlice(c.values); ok && len(values) == 0 {
+		return b.getDialect().Bool(c.not)
 	}
-	return
+
+	if c.not {
+		return c.column + " NOT IN (" + b.bind(c.values) + ")"
+	}
+	return c.column + " IN (" + b.bind(c.values) + ")"
+}
+
+type junction struct {
+	op    string
+	conds []Cond
+	empty bool
 }
 
-func Or(exp ...string) (or string) {
+func (j junction) render(b *Builder) (q string) {
+	if len(j.conds) == 0 {
+		return b.getDialect().Bool(j.empty)
+	}
+
+	exp := make([]string, len(j.conds))
+	for i, c := range j.conds {
+		exp[i] = c.render(b)
+	}
+
 	if len(exp) > 1 {
-		or += "("
+		q += "("
 	}
 
-	or += strings.Join(exp, " OR ")
+	q += strings.Join(exp, " "+j.op+" ")
 
 	if len(exp) > 1 {
-		or += ")"
+		q += ")"
 	}
 	return
 }
 
-func Eq(column, placeholder string) string {
-	return column + " = " + placeholder
+type not struct {
+	cond Cond
+}
+
+func (n not) render(b *Builder) string {
+	return "NOT (" + n.cond.render(b) + ")"
+}
+
+type expr struct {
+	sql    string
+	values []interface{}
+}
+
+func (e expr) render(b *Builder) string {
+	var q strings.Builder
+	i := 0
+	for _, r := range e.sql {
+		if r == '?' && i < len(e.values) {
+			q.WriteString(b.bind(e.values[i]))
+			i++
+		} else {
+			q.WriteRune(r)
+		}
+	}
+	return q.String()
+}
+
+// And joins the conditions with AND, it is always true without conditions.
+func And(conds ...Cond) Cond {
+	return junction{op: "AND", conds: conds, empty: true}
+}
+
+// Or joins the conditions with OR, it is always false without conditions.
+func Or(conds ...Cond) Cond {
+	return junction{op: "OR", conds: conds, empty: false}
+}
+
+// Not negates the condition.
+func Not(cond Cond) Cond {
+	return not{cond: cond}
 }
 
-func Neq(column, placeholder string) string {
-	return column + " != " + placeholder
+// Eq is column = value, or column IS NULL when value is nil.
+func Eq(column string, value interface{}) Cond {
+	return compare{column: column, op: "=", value: value}
 }
 
-func Gt(column, placeholder string) string {
-	return column + " > " + placeholder
+// Neq is column != value, or column IS NOT NULL when value is nil.
+func Neq(column string, value interface{}) Cond {
+	return compare{column: column, op: "!=", value: value}
 }
 
-func Gte(column, placeholder string) string {
-	return column + " >= " + placeholder
+func Gt(column string, value interface{}) Cond {
+	return compare{column: column, op: ">", value: value}
 }
 
-func Lt(column, placeholder string) string {
-	return column + " < " + placeholder
+func Gte(column string, value interface{}) Cond {
+	return compare{column: column, op: ">=", value: value}
 }
 
-func Lte(column, placeholder string) string {
-	return column + " <= " + placeholder
-}
\ No newline at end of file
+func Lt(column string, value interface{}) Cond {
+	return compare{column: column, op: "<", value: value}
+}
+
+func Lte(column string, value interface{}) Cond {
+	return compare{column: column, op: "<=", value: value}
+}
+
+// In is column IN (values...), values being a slice whose elements are bound one by one.
+// It is always false when the slice is empty.
+func In(column string, values interface{}) Cond {
+	return in{column: column, values: values}
+}
+
+// NotIn is column NOT IN (values...), it is always true when the slice is empty.
+func NotIn(column string, values interface{}) Cond {
+	return in{column: column, values: values, not: true}
+}
+
+// Any is column = ANY(values), values being bound as a single Postgres array, so the statement is
+// the same whatever the number of values.
+func Any(column string, values interface{}) Cond {
+	return Expr(column+" = ANY(?)", Array(values))
+}
+
+// Array wraps a slice so it is bound as a single Postgres array instead of being expanded into
+// one parameter per element, as in SetParameter(0, dal.Array(ids)) for "id = ANY(?)".
+func Array(values interface{}) interface{} {
+	return pq.Array(values)
+}
+
+var (
+	inList = regexp.MustCompile(`(?i)\bIN\s*\(\s*$`)
+	// the operand of an IN is a column, or a function call or a tuple without nested parentheses
+	emptyInList  = regexp.MustCompile(`(?i)(?:[\w."\x60\[\]` + identMark + `]+|\w*\([^()]*\))\s+(NOT\s+)?IN\s*\(\s*$`)
+	emptyInClose = regexp.MustCompile(`^\s*\)`)
+)
+
+// describeParam names a parameter in the errors of Build, the values of a Cond have no name.
+func describeParam(param string) string {
+	if strings.HasPrefix(param, condMark) {
+		return "of a Cond"
+	}
+	return param
+}
+
+// expandSlice returns the elements of v when it is a slice bound as a list of parameters, the byte
+// slices and the driver.Valuer are bound as a single value.
+func expandSlice(v interface{}) ([]interface{}, bool) {
+	if v == nil {
+		return nil, false
+	}
+
+	if _, ok := v.(driver.Valuer); ok {
+		return nil, false
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice || rv.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+
+	values := make([]interface{}, rv.Len())
+	for i := range values {
+		values[i] = rv.Index(i).Interface()
+	}
+	return values, true
+}
+
+// Expr is a condition written in SQL whose ? are bound in order to values, such as
+// Expr("lower(name) = lower(?)", name).
+func Expr(sql string, values ...interface{}) Cond {
+	return expr{sql: sql, values: values}
+}
+
+// bind keeps the value of a Cond and returns the mark replaced by its placeholder at Build.
+func (b *Builder) bind(v interface{}) string {
+	b.condValues = append(b.condValues, v)
+	return condMark + strconv.Itoa(len(b.condValues)-1) + condMark
+}
+
+// condition returns the SQL of a condition given as a string or a Cond.
+func (b *Builder) condition(c interface{}) string {
+	switch c := c.(type) {
+	case string:
+		return c
+	case Cond:
+		return c.render(b)
+	}
+	panic(fmt.Sprintf("dal: a condition must be a string or a Cond, got %T", c))
+}
@@ -6,14 +6,131 @@ import (
 )
 
 func TestExp(t *testing.T) {
-	current := And(
+	b := NewBuilder()
+	b.Select("*").From("test").Where(And(
 		Or(
-			Eq("column_1", "?"),
-			Neq("column_2", "?")),
-		Gt("column_3", "?"),
-		Lte("column_4", "?"))
+			Eq("column_1", 1),
+			Neq("column_2", "a")),
+		Gt("column_3", 3),
+		Lte("column_4", 4)))
+	b.Build()
 
-	expected := "((column_1 = ? OR column_2 != ?) AND column_3 > ? AND column_4 <= ?)"
+	expected := "SELECT * FROM test WHERE (((column_1 = $1 OR column_2 != $2) AND column_3 > $3 AND column_4 <= $4))"
 
-	assert.Equal(t, expected, current)
+	assert.Equal(t, expected, b.GetSQL())
+	assert.Equal(t, []interface{}{1, "a", 3, 4}, b.GetParameters())
+}
+
+func TestCond(t *testing.T) {
+	b := NewBuilder()
+	b.Select("name", "COUNT(1)").From("test").
+		Where("active = ?").
+		Where(Eq("deleted_at", nil)).
+		OrWhere(Not(Or(Eq("name", "a"), Expr("lower(code) = lower(?)", "X")))).
+		Where("kind = :kind").
+		GroupBy("name").
+		Having(Gte("COUNT(1)", 2)).
+		SetParameter(0, true).
+		SetParameter("kind", "k")
+	b.Build()
+
+	assert.Equal(t, "SELECT name, COUNT(1) FROM test WHERE ((active = $1) AND (deleted_at IS NULL) OR (NOT ((name = $2 OR lower(code) = lower($3)))) AND (kind = $4)) GROUP BY name HAVING COUNT(1) >= $5", b.GetSQL())
+	assert.Equal(t, []interface{}{true, "a", "X", "k", 2}, b.GetParameters())
+
+	b = NewBuilder()
+	b.Update("test").Set("name", "?").Where(And()).SetParameter(0, "a")
+	b.Build()
+
+	assert.Equal(t, "UPDATE test SET name = $1 WHERE (TRUE)", b.GetSQL())
+
+	b = NewBuilder().Dialect(MySQL)
+	b.Delete("test").Where(Or()).OrWhere(Neq("id", nil))
+	b.Build()
+
+	assert.Equal(t, "DELETE FROM test  WHERE ((FALSE) OR (id IS NOT NULL))", b.GetSQL())
+
+	assert.Panics(t, func() {
+		NewBuilder().Select("*").From("test").Where(1)
+	})
+
+	// the values are data, a value looking like a placeholder is bound as it is
+	b = NewBuilder()
+	b.Select("*").From("test").Where(Eq("name", "?")).Where(Expr("id = ?")).SetParameter(0, 5)
+	b.Build()
+
+	assert.Equal(t, "SELECT * FROM test WHERE ((name = $1) AND (id = $2))", b.GetSQL())
+	assert.Equal(t, []interface{}{"?", 5}, b.GetParameters())
+}
+
+func TestIn(t *testing.T) {
+	b := NewBuilder()
+	b.Select("*").From("test").
+		Where(In("id", []int{1, 2, 3})).
+		Where(NotIn("name", []string{})).
+		Where(In("kind", "a")).
+		Where(Any("code", []string{"x", "y"}))
+	b.Build()
+
+	assert.Equal(t, "SELECT * FROM test WHERE ((id IN ($1, $2, $3)) AND (TRUE) AND (kind IN ($4)) AND (code = ANY($5)))", b.GetSQL())
+	assert.Equal(t, []interface{}{1, 2, 3, "a", Array([]string{"x", "y"})}, b.GetParameters())
+
+	b = NewBuilder()
+	b.Select("*").From("test").Where(In("id", []int{}))
+	b.Build()
+
+	assert.Equal(t, "SELECT * FROM test WHERE (FALSE)", b.GetSQL())
+	assert.Empty(t, b.GetParameters())
+}
+
+func TestSliceParameters(t *testing.T) {
+	b := NewBuilder().Dialect(MySQL)
+	b.Select("*").From("test").
+		Where("active = ? AND id IN (?)").
+		Where("kind IN (:kinds) AND data = ?").
+		SetParameter(0, true).
+		SetParameter(1, []int64{4, 5}).
+		SetParameter(2, []byte("raw")).
+		SetParameter("kinds", []string{"a", "b"})
+	b.Build()
+
+	assert.Equal(t, "SELECT * FROM test WHERE ((active = ? AND id IN (?, ?)) AND (kind IN (?, ?) AND data = ?))", b.GetSQL())
+	assert.Equal(t, []interface{}{true, int64(4), int64(5), "a", "b", []byte("raw")}, b.GetParameters())
+
+	b = NewBuilder()
+	b.Delete("test").Where("id IN ( ? ) OR test.code NOT IN (:codes) OR id = ANY(:ids)").
+		SetParameter(0, []int{}).
+		SetParameter("codes", []string{}).
+		SetParameter("ids", Array([]int64{1, 2}))
+	b.Build()
+
+	assert.Equal(t, "DELETE FROM test  WHERE (FALSE OR TRUE OR id = ANY($1))", b.GetSQL())
+	assert.Equal(t, []interface{}{Array([]int64{1, 2})}, b.GetParameters())
+
+	b = NewBuilder()
+	b.Select("*").From("test").Where("lower(name) IN (?) OR (a, b) NOT IN (:pairs)").
+		SetParameter(0, []string{}).
+		SetParameter("pairs", []string{})
+	b.Build()
+
+	assert.Equal(t, "SELECT * FROM test WHERE (FALSE OR TRUE)", b.GetSQL())
+
+	b = NewBuilder()
+	b.SQL("SELECT * FROM f(?)").SetParameter(0, []int{})
+	_, err := b.Build()
+	assert.EqualError(t, err, "dal: parameter ? is a slice outside of an IN (...) list, use Array to bind it as a single value")
+
+	b = NewBuilder()
+	b.Select("*").From("test").Where(Eq("tags", []string{"a", "b"}))
+	_, err = b.Build()
+	assert.EqualError(t, err, "dal: parameter of a Cond is a slice outside of an IN (...) list, use Array to bind it as a single value")
+
+	b = NewBuilder()
+	b.Select("*").From("test").Where("id IN (?, ?)").SetParameter(0, 1).SetParameter(1, []int{2, 3})
+	_, err = b.Build()
+	assert.Error(t, err)
+
+	b = NewBuilder()
+	b.Select("*").From("test").Where("kind = :kind")
+	_, err = b.Build()
+	assert.EqualError(t, err, "can not find parameter with name :kind")
 }
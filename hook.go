@@ -0,0 +1,119 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+)
+
+// Hook observes every statement run by a Session or a Transaction.
+type Hook interface {
+	// BeforeQuery is called before the statement is sent, the returned context is used to run it.
+	BeforeQuery(ctx context.Context, sql string, args []interface{}) context.Context
+	// AfterQuery is called once the statement finished, rowsAffected is the number of rows
+	// changed by an exec or read by a query, or -1 when it is unknown.
+	AfterQuery(ctx context.Context, sql string, args []interface{}, duration time.Duration, rowsAffected int64, err error)
+}
+
+type hookList struct {
+	sync.RWMutex
+	hooks []Hook
+}
+
+func (l *hookList) add(h ...Hook) {
+	l.Lock()
+	l.hooks = append(l.hooks, h...)
+	l.Unlock()
+}
+
+func (l *hookList) get() []Hook {
+	l.RLock()
+	defer l.RUnlock()
+	return l.hooks
+}
+
+// AddHook registers hooks invoked for every statement run through the connection.
+func (c *Connection) AddHook(h ...Hook) {
+	c.hooks.add(h...)
+}
+
+func (c *Connection) getHooks() []Hook {
+	hooks := c.hooks.get()
+
+	if c.manager != nil {
+		if global := c.manager.hooks.get(); len(global) > 0 {
+			hooks = append(append([]Hook{}, global...), hooks...)
+		}
+	}
+
+	return hooks
+}
+
+type connectionNameKey struct{}
+
+// ConnectionName returns the name the connection running the statement was registered with in the
+// connection manager, it is meant to be used by hooks.
+func ConnectionName(ctx context.Context) string {
+	name, _ := ctx.Value(connectionNameKey{}).(string)
+	return name
+}
+
+// hookedConn is the handler given to sessions and transactions, it knows the hooks of its connection.
+type hookedConn struct {
+	handlerConn
+	conn *Connection
+	tx   *sql.Tx
+	span Span
+}
+
+func noopAfter(int64, error) {}
+
+// observe applies the statement timeout, calls the BeforeQuery hooks and returns the function that
+// must be called when the statement finishes.
+func observe(ctx context.Context, handler handlerConn, sql string, args []interface{}, timeout time.Duration) (context.Context, func(rowsAffected int64, err error)) {
+	h, ok := handler.(hookedConn)
+	if !ok {
+		return ctx, noopAfter
+	}
+
+	ctx, release := h.setTimeout(ctx, timeout)
+	ctx, after := h.observe(ctx, sql, args)
+
+	return ctx, func(rowsAffected int64, err error) {
+		after(rowsAffected, err)
+		release(err)
+	}
+}
+
+func (h hookedConn) observe(ctx context.Context, sql string, args []interface{}) (context.Context, func(rowsAffected int64, err error)) {
+	ctx, span := h.startStatementSpan(ctx, sql)
+
+	hooks := h.conn.getHooks()
+	if len(hooks) == 0 && span == nil {
+		return ctx, noopAfter
+	}
+
+	ctx = context.WithValue(ctx, connectionNameKey{}, h.conn.name)
+
+	contexts := make([]context.Context, len(hooks))
+	for i, hook := range hooks {
+		if c := hook.BeforeQuery(ctx, sql, args); c != nil {
+			ctx = c
+		}
+		contexts[i] = ctx
+	}
+
+	start := time.Now()
+
+	return ctx, func(rowsAffected int64, err error) {
+		duration := time.Since(start)
+		for i := len(hooks) - 1; i >= 0; i-- {
+			hooks[i].AfterQuery(contexts[i], sql, args, duration, rowsAffected, err)
+		}
+		if span != nil {
+			span.SetAttribute("db.rows_affected", rowsAffected)
+			endSpan(span, err)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package dal
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type hookKey struct{}
+
+type recordHook struct {
+	name    string
+	entries []string
+}
+
+func (h *recordHook) BeforeQuery(ctx context.Context, sql string, args []interface{}) context.Context {
+	h.entries = append(h.entries, "before "+sql)
+	return context.WithValue(ctx, hookKey{}, h.name)
+}
+
+func (h *recordHook) AfterQuery(ctx context.Context, sql string, args []interface{}, duration time.Duration, rowsAffected int64, err error) {
+	h.entries = append(h.entries, fmt.Sprintf("after %s %v %d %v %v", sql, args, rowsAffected, err, ctx.Value(hookKey{})))
+}
+
+func TestHooks(t *testing.T) {
+	f := &fakeDB{
+		query: fakeRowsResult([]string{"id"}, []driver.Value{int64(1)}, []driver.Value{int64(2)}),
+		exec: func(string, []driver.Value) (driver.Result, error) {
+			return driver.RowsAffected(4), nil
+		},
+	}
+
+	m := &connectionManager{}
+	global := &recordHook{name: "global"}
+	m.AddHook(global)
+
+	conn := newFakeConnection(f)
+	conn.manager = m
+	local := &recordHook{name: "local"}
+	conn.AddHook(local)
+
+	sess := conn.GetSession()
+
+	var b Builder
+	b.Select("id").From("test").Where("id > ?").SetParameter(0, 0).Build()
+
+	_, err := sess.Query(b)
+	assert.NoError(t, err)
+
+	b = Builder{}
+	b.Delete("test").Build()
+
+	assert.NoError(t, sess.Exec(b))
+
+	tx, err := conn.GetTransaction()
+	assert.NoError(t, err)
+
+	child, err := tx.Begin()
+	assert.NoError(t, err)
+	assert.NoError(t, child.Commit())
+	assert.NoError(t, tx.Commit())
+
+	assert.Equal(t, []string{
+		"before SELECT id FROM test WHERE (id > $1)",
+		"after SELECT id FROM test WHERE (id > $1) [0] 2 <nil> local",
+		"before DELETE FROM test ",
+		"after DELETE FROM test  [] 4 <nil> local",
+		`before SAVEPOINT "dal_savepoint_1"`,
+		`after SAVEPOINT "dal_savepoint_1" [] 4 <nil> local`,
+		`before RELEASE SAVEPOINT "dal_savepoint_1"`,
+		`after RELEASE SAVEPOINT "dal_savepoint_1" [] 4 <nil> local`,
+	}, local.entries)
+
+	assert.Equal(t, "after SELECT id FROM test WHERE (id > $1) [0] 2 <nil> global", global.entries[1])
+	assert.Len(t, global.entries, len(local.entries))
+}
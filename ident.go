@@ -0,0 +1,49 @@
+package dal
+
+import (
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+// identMark delimits the identifiers returned by Ident, their parts are hex encoded so they are not
+// mistaken for parameters before being quoted.
+const identMark = "\x00"
+
+var identMarker = regexp.MustCompile(identMark + `([0-9a-f.]*)` + identMark)
+
+// Ident returns an identifier, such as Ident("schema", "table"), that the builders quote with the
+// rules of their dialect. It can be used wherever a builder accepts a table, a column or a condition,
+// while the plain strings are still written verbatim.
+func Ident(parts ...string) string {
+	encoded := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p != "" {
+			encoded = append(encoded, hex.EncodeToString([]byte(p)))
+		}
+	}
+	return identMark + strings.Join(encoded, ".") + identMark
+}
+
+// Col returns the column of a table or alias, such as Col("t1", "order").
+func Col(table, column string) string {
+	return Ident(table, column)
+}
+
+// quoteIdents replaces the identifiers returned by Ident with their quoted form.
+func (b *Builder) quoteIdents(sql string) string {
+	if !strings.Contains(sql, identMark) {
+		return sql
+	}
+
+	d := b.getDialect()
+
+	return identMarker.ReplaceAllStringFunc(sql, func(m string) string {
+		parts := strings.Split(strings.Trim(m, identMark), ".")
+		for i, p := range parts {
+			name, _ := hex.DecodeString(p)
+			parts[i] = d.QuoteIdent(string(name))
+		}
+		return strings.Join(parts, ".")
+	})
+}
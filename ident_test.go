@@ -0,0 +1,30 @@
+package dal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdent(t *testing.T) {
+	b := NewBuilder()
+	b.Select(Col("t1", "order"), "t1.name").From(Ident("sales", "Order")+" t1").
+		Where(Col("t1", "user")+" = ?").OrderASC(Ident("order")).SetParameter(0, 1)
+	b.Build()
+
+	assert.Equal(t, `SELECT "t1"."order", t1.name FROM "sales"."Order" t1 WHERE ("t1"."user" = $1) ORDER BY "order" ASC`, b.GetSQL())
+	assert.Equal(t, `SELECT COUNT(1)  FROM "sales"."Order" t1 WHERE ("t1"."user" = $1)`, b.b.(*SelectBuilder).GetCountSQL())
+
+	b = NewBuilder().Dialect(MySQL)
+	b.Insert(Ident("sales", "order")).Columns(Ident("user"), "total").SetParameter(0, 1).SetParameter(1, 2)
+	b.Build()
+
+	assert.Equal(t, "INSERT INTO `sales`.`order`(`user`, total) VALUES (?, ?)", b.GetSQL())
+
+	b = NewBuilder()
+	b.Update(Ident("user")).Set(Ident("name?:x"), "?").Where("id = :id").SetParameter(0, "a").SetParameter("id", 3)
+	b.Build()
+
+	assert.Equal(t, `UPDATE "user" SET "name?:x" = $1 WHERE (id = $2)`, b.GetSQL())
+	assert.Equal(t, []interface{}{"a", 3}, b.GetParameters())
+}
@@ -11,8 +11,8 @@ INSERT Section
 */
 
 type InsertBuilder struct {
-	b            *Builder
-	returnLastId bool
+	b         *Builder
+	returning []string
 }
 
 func (b *InsertBuilder) Column(column, parameter string) *InsertBuilder {
@@ -75,6 +75,10 @@ func (b *InsertBuilder) Type(entity interface{}) *InsertBuilder {
 			}
 		}
 
+		if config["sensitive"] {
+			value = Sensitive(value)
+		}
+
 		columnNames = append(columnNames, columnName)
 		b.SetParameter(count, value)
 		count += 1
@@ -112,7 +116,22 @@ func (b *InsertBuilder) Columns(columns ...string) *InsertBuilder {
 }
 
 func (b *InsertBuilder) LastInsertId() *InsertBuilder {
-	b.returnLastId = true
+	return b.Returning("id")
+}
+
+//Returning - columns sent back by the server after the insert
+func (b *InsertBuilder) Returning(columns ...string) *InsertBuilder {
+	for _, c := range columns {
+		exist := false
+		for _, r := range b.returning {
+			if r == c {
+				exist = true
+			}
+		}
+		if !exist {
+			b.returning = append(b.returning, c)
+		}
+	}
 
 	return b
 }
@@ -133,6 +152,11 @@ func (b *InsertBuilder) GetSQL() (q string) {
 		q += bSelect.getSQL()
 	}
 
+	var output, suffix string
+	if len(b.returning) > 0 {
+		output, suffix, _ = b.b.getDialect().Returning(b.returning)
+	}
+
 	if ok, bSelect := b.b.getPart(columnsPartEnum); ok {
 		cols := make([]string, len(bSelect.(columnPartSQL).parts))
 		vals := make([]string, len(cols))
@@ -140,13 +164,11 @@ func (b *InsertBuilder) GetSQL() (q string) {
 			cols[i] = o.name
 			vals[i] = o.parameter
 		}
-		q += "(" + strings.Join(cols, ", ") + ") "
+		q += "(" + strings.Join(cols, ", ") + ")" + output + " "
 		q += "VALUES (" + strings.Join(vals, ", ") + ")"
 	}
 
-	if b.returnLastId {
-		q += " RETURNING id"
-	}
+	q += suffix
 
 	return
 }
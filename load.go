@@ -53,6 +53,9 @@ func load(rows *sql.Rows, value interface{}, oneResult bool) (int, error) {
 			break
 		}
 	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
 	return count, nil
 }
 
@@ -0,0 +1,99 @@
+package dal
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const redacted = "***"
+
+type sensitiveValue struct {
+	v interface{}
+}
+
+// Sensitive marks a parameter whose value must never be written to the logs.
+// Columns tagged with `db:"name,sensitive"` are marked by InsertBuilder.Type and UpdateBuilder.Type.
+func Sensitive(v interface{}) driver.Valuer {
+	if s, ok := v.(sensitiveValue); ok {
+		return s
+	}
+	return sensitiveValue{v: v}
+}
+
+// Value implements the driver Valuer interface, the real value is only given to the driver.
+func (s sensitiveValue) Value() (driver.Value, error) {
+	if v, ok := s.v.(driver.Valuer); ok {
+		return v.Value()
+	}
+	return driver.DefaultParameterConverter.ConvertValue(s.v)
+}
+
+func (s sensitiveValue) String() string {
+	return redacted
+}
+
+// Printer is the minimal logger used by QueryLogger, *log.Logger satisfies it.
+type Printer interface {
+	Printf(format string, v ...interface{})
+}
+
+// QueryLogger is a Hook that writes one line per statement with its SQL, parameters, duration and error.
+// Statements slower than SlowThreshold are logged with level warn, a zero threshold disables it.
+type QueryLogger struct {
+	Logger        Printer
+	SlowThreshold time.Duration
+}
+
+// NewQueryLogger returns a QueryLogger writing to l.
+func NewQueryLogger(l Printer, slowThreshold time.Duration) *QueryLogger {
+	return &QueryLogger{Logger: l, SlowThreshold: slowThreshold}
+}
+
+func (l *QueryLogger) BeforeQuery(ctx context.Context, sql string, args []interface{}) context.Context {
+	return ctx
+}
+
+func (l *QueryLogger) AfterQuery(ctx context.Context, sql string, args []interface{}, duration time.Duration, rowsAffected int64, err error) {
+	level, msg := "info", "query"
+
+	if err != nil {
+		level, msg = "error", "query failed"
+	} else if l.SlowThreshold > 0 && duration >= l.SlowThreshold {
+		level, msg = "warn", "slow query"
+	}
+
+	line := fmt.Sprintf("level=%s msg=%q duration=%s rows=%d sql=%q args=%s", level, msg, duration, rowsAffected, sql, formatArgs(args))
+
+	if err != nil {
+		line += fmt.Sprintf(" err=%q", err.Error())
+	}
+
+	l.Logger.Printf("%s", line)
+}
+
+func formatArgs(args []interface{}) string {
+	values := make([]string, len(args))
+
+	for i, a := range args {
+		switch v := a.(type) {
+		case sensitiveValue:
+			values[i] = redacted
+		case nil:
+			values[i] = "NULL"
+		case string:
+			values[i] = strconv.Quote(v)
+		case []byte:
+			values[i] = strconv.Quote(string(v))
+		case time.Time:
+			values[i] = strconv.Quote(v.Format(time.RFC3339Nano))
+		default:
+			values[i] = fmt.Sprintf("%v", v)
+		}
+	}
+
+	return "[" + strings.Join(values, ", ") + "]"
+}
@@ -0,0 +1,66 @@
+package dal
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type account struct {
+	Id       int64  `db:"id,autoincrement"`
+	User     string `db:"user"`
+	Password string `db:"password,sensitive"`
+}
+
+func TestQueryLogger(t *testing.T) {
+	var received []driver.Value
+	f := &fakeDB{exec: func(q string, args []driver.Value) (driver.Result, error) {
+		received = args
+		if strings.HasPrefix(q, "DELETE") {
+			return nil, errors.New("boom")
+		}
+		return driver.RowsAffected(1), nil
+	}}
+
+	var buf bytes.Buffer
+	conn := newFakeConnection(f)
+	conn.AddHook(NewQueryLogger(log.New(&buf, "", 0), time.Hour))
+
+	sess := conn.GetSession()
+
+	var b Builder
+	b.Insert("account").Type(account{User: "daniel", Password: "secret"}).Build()
+
+	assert.NoError(t, sess.Exec(b))
+	assert.Equal(t, []driver.Value{"daniel", "secret"}, received)
+
+	b = Builder{}
+	b.Delete("account").Where("id = ?").SetParameter(0, 1).Build()
+
+	assert.Error(t, sess.Exec(b))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	assert.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], `level=info msg="query" duration=`))
+	assert.True(t, strings.HasSuffix(lines[0], `rows=1 sql="INSERT INTO account(user, password) VALUES ($1, $2)" args=["daniel", ***]`), lines[0])
+	assert.NotContains(t, buf.String(), "secret")
+	assert.True(t, strings.HasPrefix(lines[1], `level=error msg="query failed"`))
+	assert.True(t, strings.HasSuffix(lines[1], `args=[1] err="boom"`), lines[1])
+}
+
+func TestQueryLoggerSlow(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewQueryLogger(log.New(&buf, "", 0), time.Millisecond)
+
+	l.AfterQuery(context.Background(), "SELECT 1", nil, time.Second, 1, nil)
+
+	assert.Equal(t, `level=warn msg="slow query" duration=1s rows=1 sql="SELECT 1" args=[]`+"\n", buf.String())
+}
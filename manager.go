@@ -1,11 +1,12 @@
 package dal
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	_ "github.com/GoogleCloudPlatform/cloudsql-proxy/proxy/dialers/postgres"
 	_ "github.com/lib/pq"
-	"strconv"
+	"sort"
 	"sync"
 )
 
@@ -14,89 +15,260 @@ const UNIQUE_CONNECTION = "DAL_UNIQUE"
 var once sync.Once
 var instance *connectionManager
 
+var _ Manager = (*connectionManager)(nil)
+
+// Manager registers the connections by name, it is implemented by the managers returned by
+// NewConnectionManager and GetConnectionManager.
+type Manager interface {
+	AddSingleDB(c map[string]string) error
+	AddDB(name string, c map[string]string, replicas ...map[string]string) error
+	AddConfig(name string, c Config, replicas ...Config) error
+	Reconfigure(name string, c Config, replicas ...Config) error
+	GetSingleConnection() *Connection
+	GetConnection(name string) *Connection
+	ConnectionNames() []string
+	GetSession() *Session
+	GetTransaction(opts ...TxOptions) (*Transaction, error)
+	GetTransactionContext(ctx context.Context, opts *TxOptions) (*Transaction, error)
+	AddHook(h ...Hook)
+	SetTracer(t Tracer)
+	RemoveDB(name string) error
+	CloseAll() error
+}
+
 type connectionManager struct {
 	configured  bool
 	connections map[string]*Connection
+	hooks       hookList
+	tracer      Tracer
 	sync.Mutex
 }
 
+// NewConnectionManager returns a manager independent of the default one, for instance to give each
+// test its own databases.
+func NewConnectionManager() Manager {
+	return &connectionManager{connections: make(map[string]*Connection)}
+}
+
+// GetConnectionManager returns the default manager of the process.
 func GetConnectionManager() *connectionManager {
 	once.Do(func() {
-		instance = &connectionManager{}
-		instance.connections = make(map[string]*Connection)
+		instance = &connectionManager{connections: make(map[string]*Connection)}
 	})
 	return instance
 }
 
 func (m *connectionManager) AddSingleDB(c map[string]string) error {
-	return m.configure(UNIQUE_CONNECTION, c)
+	return m.AddDB(UNIQUE_CONNECTION, c)
+}
+
+// AddDB registers the connection name, the optional replicas receive the reads of its sessions.
+// The keys of the maps are read as in Config, the unknown ones are passed to the driver.
+func (m *connectionManager) AddDB(name string, c map[string]string, replicas ...map[string]string) error {
+	config, err := configFromMap(c)
+
+	if err != nil {
+		return err
+	}
+
+	replicaConfigs := make([]Config, len(replicas))
+
+	for i, r := range replicas {
+		if replicaConfigs[i], err = configFromMap(r); err != nil {
+			return err
+		}
+	}
+
+	return m.AddConfig(name, config, replicaConfigs...)
+}
+
+// AddConfig registers the connection name, the optional replicas receive the reads of its sessions.
+// A connection already registered with the same name is kept, use Reconfigure to replace it.
+func (m *connectionManager) AddConfig(name string, c Config, replicas ...Config) error {
+	return m.configure(name, c, replicas)
+}
+
+// Reconfigure replaces the pool and the replicas of the connection name by ones opened with c and
+// replicas, for instance to rotate their credentials. The sessions keep working on the new pools, the
+// transactions started before finish on the previous ones, which are closed once they are done.
+func (m *connectionManager) Reconfigure(name string, c Config, replicas ...Config) error {
+	conn := m.GetConnection(name)
+
+	if conn == nil {
+		return fmt.Errorf("dal: connection %q is not configured", name)
+	}
+
+	db, err := open(c)
+
+	if err != nil {
+		return err
+	}
+
+	opened := make([]*replica, 0, len(replicas))
+
+	for _, r := range replicas {
+		rdb, err := open(r)
+
+		if err != nil {
+			db.Close()
+			for _, o := range opened {
+				o.db.Close()
+			}
+			return err
+		}
+
+		opened = append(opened, &replica{db: rdb})
+	}
+
+	go conn.swap(db, opened, c.ReplicaPolicy)()
+
+	return nil
 }
 
-func (m *connectionManager) AddDB(name string, c map[string]string) error {
-	return m.configure(name, c)
+// RemoveDB unregisters the connection name and closes it.
+func (m *connectionManager) RemoveDB(name string) error {
+	m.Lock()
+	conn, ok := m.connections[name]
+	delete(m.connections, name)
+	m.Unlock()
+
+	if !ok {
+		return fmt.Errorf("dal: connection %q is not configured", name)
+	}
+
+	return conn.Close()
+}
+
+// CloseAll unregisters and closes every connection, returning the first error found.
+func (m *connectionManager) CloseAll() error {
+	m.Lock()
+	conns := m.connections
+	m.connections = make(map[string]*Connection)
+	m.configured = false
+	m.Unlock()
+
+	var first error
+
+	for _, conn := range conns {
+		if err := conn.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return first
 }
 
 func (m *connectionManager) GetSingleConnection() *Connection {
-	return m.connections[UNIQUE_CONNECTION]
+	return m.GetConnection(UNIQUE_CONNECTION)
 }
 
 func (m *connectionManager) GetConnection(name string) *Connection {
+	m.Lock()
+	defer m.Unlock()
 	return m.connections[name]
 }
 
-func (m *connectionManager) GetSession() *Session {
-	return m.connections[UNIQUE_CONNECTION].GetSession()
+// ConnectionNames returns the names of the registered connections in alphabetical order.
+func (m *connectionManager) ConnectionNames() []string {
+	m.Lock()
+	names := make([]string, 0, len(m.connections))
+	for name := range m.connections {
+		names = append(names, name)
+	}
+	m.Unlock()
+
+	sort.Strings(names)
+
+	return names
 }
 
-func (m *connectionManager) GetTransaction() (*Transaction, error) {
-	return m.connections[UNIQUE_CONNECTION].GetTransaction()
+func (m *connectionManager) GetSession() *Session {
+	return m.GetSingleConnection().GetSession()
 }
 
-func (m *connectionManager) configure(name string, config map[string]string) error {
+func (m *connectionManager) GetTransaction(opts ...TxOptions) (*Transaction, error) {
+	return m.GetSingleConnection().GetTransaction(opts...)
+}
 
-	m.Lock()
+func (m *connectionManager) GetTransactionContext(ctx context.Context, opts *TxOptions) (*Transaction, error) {
+	return m.GetSingleConnection().GetTransactionContext(ctx, opts)
+}
 
-	dsn := fmt.Sprintf("host=%s port=%s dbname=%s user=%s password=%s sslmode=%s", config["host"], config["port"], config["database"], config["user"], config["password"], config["ssl"])
+// AddHook registers hooks invoked for every statement run through any connection of the manager.
+func (m *connectionManager) AddHook(h ...Hook) {
+	m.hooks.add(h...)
+}
 
-	var conn *sql.DB
-	var err error
+// SetTracer sets the tracer used by the connections that do not have their own.
+func (m *connectionManager) SetTracer(t Tracer) {
+	m.tracer = t
+}
 
-	if driver, b := config["driver"]; b {
-		conn, err = sql.Open(driver, dsn)
-	} else {
-		conn, err = sql.Open("postgres", dsn)
-	}
+// configure opens the pool and the replicas and registers them as name, unless a connection with that
+// name exists. Nothing is registered when one of them can not be opened.
+func (m *connectionManager) configure(name string, config Config, replicas []Config) error {
+	db, err := open(config)
 
 	if err != nil {
 		return err
 	}
 
-	if val, ok := config["maxOpenConns"]; ok {
-		if max, err := strconv.Atoi(val); err != nil {
-			conn.SetMaxOpenConns(max)
+	conn := &Connection{db: db, users: &sync.WaitGroup{}, manager: m, name: name, dialect: dialectForDriver(config.driver()), replicaPolicy: config.ReplicaPolicy}
+
+	for _, r := range replicas {
+		rdb, err := open(r)
+
+		if err != nil {
+			conn.Close()
+			return err
 		}
+
+		conn.replicas = append(conn.replicas, &replica{db: rdb})
 	}
 
-	if val, ok := config["maxIdleConns"]; ok {
-		if max, err := strconv.Atoi(val); err != nil {
-			conn.SetMaxIdleConns(max)
-		}
+	m.Lock()
+	defer m.Unlock()
+
+	m.configured = true
+
+	if _, ok := m.connections[name]; ok {
+		conn.Close()
+		return nil
 	}
 
-	err = conn.Ping()
+	m.connections[name] = conn
+
+	return nil
+}
+
+func open(config Config) (*sql.DB, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	conn, err := sql.Open(config.driver(), config.DSN())
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if _, ok := m.connections[name]; !ok {
-		m.connections[name] = &Connection{db: conn}
+	if config.MaxOpenConns > 0 {
+		conn.SetMaxOpenConns(config.MaxOpenConns)
 	}
 
-	m.configured = true
+	if config.MaxIdleConns > 0 {
+		conn.SetMaxIdleConns(config.MaxIdleConns)
+	}
 
-	m.Unlock()
+	conn.SetConnMaxLifetime(config.ConnMaxLifetime)
+	conn.SetConnMaxIdleTime(config.ConnMaxIdleTime)
 
-	return nil
+	err = conn.Ping()
+
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
 
+	return conn, nil
 }
@@ -0,0 +1,117 @@
+package dal
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigValidate(t *testing.T) {
+	m := NewConnectionManager()
+
+	err := m.AddDB("main", map[string]string{"driver": "dalfake", "maxOpenConns": "2", "maxIdleConns": "5"})
+	assert.EqualError(t, err, "dal: maxIdleConns (5) must not exceed maxOpenConns (2)")
+
+	err = m.AddDB("main", map[string]string{"driver": "dalfake", "maxOpenConns": "-1"})
+	assert.EqualError(t, err, "dal: maxOpenConns must not be negative, got -1")
+
+	err = m.AddDB("main", map[string]string{"driver": "dalfake", "connMaxLifetime": "forever"})
+	assert.EqualError(t, err, `dal: invalid connMaxLifetime "forever", expected a duration such as 30m`)
+
+	// a failed open must not leave the manager locked
+	err = m.AddDB("main", map[string]string{"driver": "dalfake", "host": "nowhere"})
+	assert.Error(t, err)
+	assert.Nil(t, m.GetConnection("main"))
+
+	// nor a connection whose replica failed to open
+	registerFakeDB("host='primary'", &fakeDB{})
+	err = m.AddConfig("main", Config{Driver: "dalfake", Host: "primary"}, Config{Driver: "dalfake", Host: "nowhere"})
+	assert.Error(t, err)
+	assert.Nil(t, m.GetConnection("main"))
+}
+
+func TestConnectionLifecycle(t *testing.T) {
+	counter := func(f *fakeDB) *fakeDB {
+		f.exec = func(q string, args []driver.Value) (driver.Result, error) {
+			return driver.RowsAffected(1), nil
+		}
+		return f
+	}
+	first, second, replica := counter(&fakeDB{}), counter(&fakeDB{}), &fakeDB{}
+	registerFakeDB("host='first'", first)
+	registerFakeDB("host='second'", second)
+	registerFakeDB("host='replica'", replica)
+
+	m := NewConnectionManager()
+
+	err := m.AddConfig("main", Config{Driver: "dalfake", Host: "first", MaxOpenConns: 3, MaxIdleConns: 1, ConnMaxLifetime: time.Minute})
+	assert.NoError(t, err)
+
+	conn := m.GetConnection("main")
+	assert.Equal(t, 3, conn.getDB().Stats().MaxOpenConnections)
+
+	// registering the name again keeps the connection
+	assert.NoError(t, m.AddConfig("main", Config{Driver: "dalfake", Host: "second"}))
+	assert.Equal(t, conn, m.GetConnection("main"))
+
+	conn.SetStmtCacheSize(4)
+
+	var b Builder
+	b.Delete("test").Build()
+
+	old := conn.GetSession()
+	assert.NoError(t, old.Exec(b))
+
+	tx, err := conn.GetTransaction()
+	assert.NoError(t, err)
+
+	assert.EqualError(t, m.Reconfigure("other", Config{Driver: "dalfake", Host: "second"}), `dal: connection "other" is not configured`)
+	assert.NoError(t, m.Reconfigure("main", Config{Driver: "dalfake", Host: "second"}, Config{Driver: "dalfake", Host: "replica"}))
+
+	// the session obtained before follows the new pools, the transaction ends on the previous one
+	assert.NoError(t, old.Exec(b))
+	assert.NoError(t, tx.Exec(b))
+	assert.NoError(t, tx.Commit())
+	assert.NoError(t, conn.GetSession().Exec(b))
+
+	var sel Builder
+	sel.Select("id").From("test").Build()
+
+	_, err = old.Query(sel)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"DELETE FROM test ", "BEGIN", "DELETE FROM test ", "COMMIT"}, first.Statements())
+	assert.Equal(t, []string{"DELETE FROM test ", "DELETE FROM test "}, second.Statements())
+	assert.Equal(t, []string{"SELECT id FROM test"}, replica.Statements())
+
+	assert.NoError(t, m.RemoveDB("main"))
+	assert.Nil(t, m.GetConnection("main"))
+	assert.Error(t, m.RemoveDB("main"))
+
+	assert.NoError(t, m.AddConfig("a", Config{Driver: "dalfake", Host: "first"}))
+	assert.NoError(t, m.AddConfig("b", Config{Driver: "dalfake", Host: "second"}))
+	assert.NoError(t, m.CloseAll())
+	assert.Nil(t, m.GetConnection("a"))
+	assert.Nil(t, m.GetConnection("b"))
+}
+
+func TestManagersAreIndependent(t *testing.T) {
+	registerFakeDB("host='independent'", &fakeDB{})
+
+	var a, b Manager = NewConnectionManager(), NewConnectionManager()
+
+	assert.NoError(t, a.AddConfig(UNIQUE_CONNECTION, Config{Driver: "dalfake", Host: "independent"}))
+	assert.NotNil(t, a.GetSession())
+	assert.Equal(t, []string{UNIQUE_CONNECTION}, a.ConnectionNames())
+	assert.Nil(t, b.GetConnection(UNIQUE_CONNECTION))
+	assert.Empty(t, b.ConnectionNames())
+
+	metrics := NewMetrics(a)
+	a.AddHook(metrics)
+	assert.Len(t, metrics.pools(), 1)
+
+	assert.NotEqual(t, GetConnectionManager(), a)
+	assert.NoError(t, a.CloseAll())
+}
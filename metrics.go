@@ -0,0 +1,274 @@
+package dal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	_ Hook         = (*Metrics)(nil)
+	_ expvar.Var   = (*Metrics)(nil)
+	_ http.Handler = (*Metrics)(nil)
+)
+
+// DefaultLatencyBuckets are the upper bounds, in seconds, of the latency histogram.
+var DefaultLatencyBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+var (
+	normalizeLiterals    = regexp.MustCompile(`'(?:[^']|'')*'|\$\d+|\b\d+(?:\.\d+)?\b`)
+	normalizeInLists     = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)+\s*\)`)
+	normalizeWhitespaces = regexp.MustCompile(`\s+`)
+)
+
+// NormalizeSQL replaces literals and placeholders by ? and collapses the whitespaces so statements
+// that only differ on their values are grouped together.
+func NormalizeSQL(sql string) string {
+	sql = normalizeLiterals.ReplaceAllString(sql, "?")
+	sql = normalizeInLists.ReplaceAllString(sql, "(?)")
+	sql = normalizeWhitespaces.ReplaceAllString(sql, " ")
+	return strings.TrimSpace(sql)
+}
+
+// Fingerprint returns a short identifier of the normalized statement.
+func Fingerprint(sql string) string {
+	h := fnv.New64a()
+	h.Write([]byte(NormalizeSQL(sql)))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+type seriesKey struct {
+	connection, fingerprint string
+}
+
+type querySeries struct {
+	sql     string
+	count   uint64
+	errors  uint64
+	sum     float64
+	buckets []uint64
+}
+
+// Metrics is a Hook that counts the statements, their errors and latencies by connection and fingerprint.
+// It can be published with expvar and served in the Prometheus text format.
+type Metrics struct {
+	mu      sync.Mutex
+	buckets []float64
+	series  map[seriesKey]*querySeries
+	manager Manager
+}
+
+// NewMetrics returns a collector that also reports the pool statistics of the connections of m,
+// which may be nil.
+func NewMetrics(m Manager) *Metrics {
+	return &Metrics{
+		buckets: DefaultLatencyBuckets,
+		series:  make(map[seriesKey]*querySeries),
+		manager: m,
+	}
+}
+
+func (m *Metrics) BeforeQuery(ctx context.Context, sql string, args []interface{}) context.Context {
+	return ctx
+}
+
+func (m *Metrics) AfterQuery(ctx context.Context, sql string, args []interface{}, duration time.Duration, rowsAffected int64, err error) {
+	key := seriesKey{connection: ConnectionName(ctx), fingerprint: Fingerprint(sql)}
+	seconds := duration.Seconds()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.series[key]
+	if !ok {
+		s = &querySeries{sql: NormalizeSQL(sql), buckets: make([]uint64, len(m.buckets))}
+		m.series[key] = s
+	}
+
+	s.count++
+	s.sum += seconds
+	if err != nil {
+		s.errors++
+	}
+	for i, b := range m.buckets {
+		if seconds <= b {
+			s.buckets[i]++
+		}
+	}
+}
+
+// String implements expvar.Var, use expvar.Publish(name, metrics) to publish the collector.
+func (m *Metrics) String() string {
+	type entry struct {
+		Connection  string  `json:"connection"`
+		Fingerprint string  `json:"fingerprint"`
+		SQL         string  `json:"sql"`
+		Count       uint64  `json:"count"`
+		Errors      uint64  `json:"errors"`
+		Seconds     float64 `json:"seconds"`
+	}
+
+	entries := make([]entry, 0)
+
+	for _, k := range m.keys() {
+		m.mu.Lock()
+		s := m.series[k]
+		entries = append(entries, entry{k.connection, k.fingerprint, s.sql, s.count, s.errors, s.sum})
+		m.mu.Unlock()
+	}
+
+	b, _ := json.Marshal(map[string]interface{}{"queries": entries, "pools": m.pools()})
+
+	return string(b)
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+
+	m.writeQueries(&buf)
+	m.writePools(&buf)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write(buf.Bytes())
+}
+
+func (m *Metrics) keys() []seriesKey {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make([]seriesKey, 0, len(m.series))
+	for k := range m.series {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].connection != keys[j].connection {
+			return keys[i].connection < keys[j].connection
+		}
+		return keys[i].fingerprint < keys[j].fingerprint
+	})
+
+	return keys
+}
+
+func (m *Metrics) writeQueries(buf *bytes.Buffer) {
+	keys := m.keys()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	buf.WriteString("# HELP dal_queries_total Number of statements executed.\n# TYPE dal_queries_total counter\n")
+	for _, k := range keys {
+		fmt.Fprintf(buf, "dal_queries_total{%s} %d\n", k.labels(), m.series[k].count)
+	}
+
+	buf.WriteString("# HELP dal_query_errors_total Number of statements that failed.\n# TYPE dal_query_errors_total counter\n")
+	for _, k := range keys {
+		fmt.Fprintf(buf, "dal_query_errors_total{%s} %d\n", k.labels(), m.series[k].errors)
+	}
+
+	buf.WriteString("# HELP dal_query_duration_seconds Latency of the statements.\n# TYPE dal_query_duration_seconds histogram\n")
+	for _, k := range keys {
+		s := m.series[k]
+		for i, b := range m.buckets {
+			fmt.Fprintf(buf, "dal_query_duration_seconds_bucket{%s,le=\"%s\"} %d\n", k.labels(), formatFloat(b), s.buckets[i])
+		}
+		fmt.Fprintf(buf, "dal_query_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", k.labels(), s.count)
+		fmt.Fprintf(buf, "dal_query_duration_seconds_sum{%s} %s\n", k.labels(), formatFloat(s.sum))
+		fmt.Fprintf(buf, "dal_query_duration_seconds_count{%s} %d\n", k.labels(), s.count)
+	}
+}
+
+type poolStats struct {
+	Connection        string  `json:"connection"`
+	MaxOpen           int     `json:"max_open"`
+	Open              int     `json:"open"`
+	InUse             int     `json:"in_use"`
+	Idle              int     `json:"idle"`
+	WaitCount         int64   `json:"wait_count"`
+	WaitSeconds       float64 `json:"wait_seconds"`
+	MaxIdleClosed     int64   `json:"max_idle_closed"`
+	MaxLifetimeClosed int64   `json:"max_lifetime_closed"`
+}
+
+func (m *Metrics) pools() []poolStats {
+	pools := make([]poolStats, 0)
+
+	if m.manager == nil {
+		return pools
+	}
+
+	for _, name := range m.manager.ConnectionNames() {
+		conn := m.manager.GetConnection(name)
+		if conn == nil {
+			continue
+		}
+
+		s := conn.getDB().Stats()
+		pools = append(pools, poolStats{
+			Connection:        name,
+			MaxOpen:           s.MaxOpenConnections,
+			Open:              s.OpenConnections,
+			InUse:             s.InUse,
+			Idle:              s.Idle,
+			WaitCount:         s.WaitCount,
+			WaitSeconds:       s.WaitDuration.Seconds(),
+			MaxIdleClosed:     s.MaxIdleClosed,
+			MaxLifetimeClosed: s.MaxLifetimeClosed,
+		})
+	}
+
+	return pools
+}
+
+func (m *Metrics) writePools(buf *bytes.Buffer) {
+	pools := m.pools()
+
+	gauges := []struct {
+		name, kind, help string
+		value            func(p poolStats) string
+	}{
+		{"dal_pool_max_open_connections", "gauge", "Maximum number of open connections.", func(p poolStats) string { return strconv.Itoa(p.MaxOpen) }},
+		{"dal_pool_open_connections", "gauge", "Number of established connections.", func(p poolStats) string { return strconv.Itoa(p.Open) }},
+		{"dal_pool_in_use_connections", "gauge", "Number of connections in use.", func(p poolStats) string { return strconv.Itoa(p.InUse) }},
+		{"dal_pool_idle_connections", "gauge", "Number of idle connections.", func(p poolStats) string { return strconv.Itoa(p.Idle) }},
+		{"dal_pool_wait_count_total", "counter", "Number of connections waited for.", func(p poolStats) string { return strconv.FormatInt(p.WaitCount, 10) }},
+		{"dal_pool_wait_seconds_total", "counter", "Time blocked waiting for a connection.", func(p poolStats) string { return formatFloat(p.WaitSeconds) }},
+		{"dal_pool_max_idle_closed_total", "counter", "Connections closed due to the idle limit.", func(p poolStats) string { return strconv.FormatInt(p.MaxIdleClosed, 10) }},
+		{"dal_pool_max_lifetime_closed_total", "counter", "Connections closed due to their lifetime.", func(p poolStats) string { return strconv.FormatInt(p.MaxLifetimeClosed, 10) }},
+	}
+
+	if len(pools) == 0 {
+		return
+	}
+
+	for _, g := range gauges {
+		fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s %s\n", g.name, g.help, g.name, g.kind)
+		for _, p := range pools {
+			fmt.Fprintf(buf, "%s{connection=\"%s\"} %s\n", g.name, escapeLabel(p.Connection), g.value(p))
+		}
+	}
+}
+
+func (k seriesKey) labels() string {
+	return "connection=\"" + escapeLabel(k.connection) + "\",fingerprint=\"" + k.fingerprint + "\""
+}
+
+func escapeLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
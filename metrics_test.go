@@ -0,0 +1,58 @@
+package dal
+
+import (
+	"database/sql/driver"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeSQL(t *testing.T) {
+	assert.Equal(t, "SELECT id FROM table_1 WHERE id = ? AND name = ? AND code IN (?)",
+		NormalizeSQL("SELECT id FROM table_1\n  WHERE id = $1 AND name = 'it''s' AND code IN (1, 2,3)"))
+
+	assert.Equal(t, Fingerprint("SELECT id FROM test WHERE id = $1"), Fingerprint("SELECT id  FROM test WHERE id = 10"))
+}
+
+func TestMetrics(t *testing.T) {
+	f := &fakeDB{exec: func(q string, args []driver.Value) (driver.Result, error) {
+		if args[0] == int64(2) {
+			return nil, errors.New("boom")
+		}
+		return driver.RowsAffected(1), nil
+	}}
+
+	m := &connectionManager{connections: make(map[string]*Connection)}
+	conn := newFakeConnection(f)
+	conn.name = "main"
+	conn.manager = m
+	m.connections["main"] = conn
+
+	metrics := NewMetrics(m)
+	m.AddHook(metrics)
+
+	sess := conn.GetSession()
+
+	for i := 1; i <= 3; i++ {
+		var b Builder
+		b.Delete("test").Where("id = ?").SetParameter(0, i).Build()
+		sess.Exec(b)
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	body := rec.Body.String()
+	labels := `connection="main",fingerprint="` + Fingerprint("DELETE FROM test WHERE (id = $1)") + `"`
+
+	assert.Contains(t, body, "# TYPE dal_queries_total counter\ndal_queries_total{"+labels+"} 3\n")
+	assert.Contains(t, body, "dal_query_errors_total{"+labels+"} 1\n")
+	assert.Contains(t, body, "dal_query_duration_seconds_bucket{"+labels+",le=\"+Inf\"} 3\n")
+	assert.Contains(t, body, "dal_query_duration_seconds_count{"+labels+"} 3\n")
+	assert.Contains(t, body, "dal_pool_open_connections{connection=\"main\"} ")
+
+	assert.True(t, strings.Contains(metrics.String(), `"sql":"DELETE FROM test WHERE (id = ?)","count":3,"errors":1`), metrics.String())
+}
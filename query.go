@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type IBuilder interface {
@@ -19,6 +20,11 @@ type Builder struct {
 	sqlParts    []part
 	params      map[interface{}]interface{}
 	finalParams []interface{}
+	tags        map[string]string
+	timeout     time.Duration
+	dialect     Dialect
+	// condValues are the values bound by the Cond conditions, in the order they were added
+	condValues []interface{}
 }
 
 func NewBuilder() *Builder {
@@ -95,7 +101,7 @@ func (b *Builder) RawBuild() (*Builder, error) {
 
 	sql := b.b.GetSQL()
 
-	b.sql = sql
+	b.sql = b.annotate(b.quoteIdents(sql))
 
 	return b, nil
 }
@@ -113,7 +119,7 @@ func (b *Builder) Build() (*Builder, error) {
 		return b, err
 	}
 
-	b.sql = sql
+	b.sql = b.annotate(b.quoteIdents(sql))
 
 	return b, nil
 }
@@ -160,25 +166,29 @@ func (b *Builder) removePart(e partEnum) bool {
 	return r > -1
 }
 
-func getPlaceHolder(consecutive int) string {
-
-	return "$" + strconv.Itoa(consecutive)
-
-}
-
 func (b *Builder) build(sql string) (string, error) {
 
 	b.finalParams = make([]interface{}, 0)
 
-	r, _ := regexp.Compile("\\?|[^:][:][a-zA-Z_\\-]+")
+	r, _ := regexp.Compile("\\?|[^:][:][a-zA-Z_\\-]+|" + condMark + "[0-9]+" + condMark)
 
 	matches := r.FindAllStringSubmatchIndex(sql, -1)
 
-	var iParam, sParam int
+	original := sql
+
+	// pos is where the parameters not yet replaced start, the placeholders written before it may
+	// look like parameters (? in MySQL)
+	var iParam, pos int
 
-	for i, v := range matches {
+	for _, v := range matches {
 		param := "?"
-		if v[1]-1 == v[0] {
+		var value interface{}
+		if strings.HasPrefix(original[v[0]:v[1]], condMark) {
+			param = original[v[0]:v[1]]
+			index, _ := strconv.Atoi(strings.Trim(param, condMark))
+
+			value = b.condValues[index]
+		} else if v[1]-1 == v[0] {
 			if _, ok := b.params[iParam]; !ok {
 				error := ""
 				if iParam == 0 {
@@ -193,25 +203,61 @@ func (b *Builder) build(sql string) (string, error) {
 					return "", fmt.Errorf(error)
 				}
 			}
-			b.finalParams = append(b.finalParams, b.params[iParam])
+			value = b.params[iParam]
 
 			iParam++
 		} else {
-			params := r.FindAllString(sql, 1)
+			params := r.FindAllString(sql[pos:], 1)
 			param = params[0]
 			r1, _ := regexp.Compile("[:][a-zA-Z0-9_\\-]+")
 			param = r1.FindAllString(param, 1)[0]
 			paramName := strings.Replace(param, ":", "", 1)
 			if _, ok := b.params[paramName]; !ok {
-				return "", fmt.Errorf("can not find parameter with name %s", params[i])
+				return "", fmt.Errorf("can not find parameter with name %s", param)
+			}
+
+			value = b.params[paramName]
+		}
+
+		index := pos + strings.Index(sql[pos:], param)
+		before, after := sql[:index], sql[index+len(param):]
+
+		values, ok := expandSlice(value)
+
+		if !ok {
+			b.finalParams = append(b.finalParams, value)
+			before += b.getDialect().Placeholder(len(b.finalParams))
+			sql, pos = before+after, len(before)
+			continue
+		}
+
+		// a slice is only expanded as the list of an IN, elsewhere it would change the meaning of the statement
+		if !inList.MatchString(before) || emptyInClose.FindString(after) == "" {
+			return "", fmt.Errorf("dal: parameter %s is a slice outside of an IN (...) list, use Array to bind it as a single value", describeParam(param))
+		}
+
+		if len(values) == 0 {
+			// an empty list can not be written, the whole IN condition is replaced by its result
+			m := emptyInList.FindStringSubmatch(before)
+			closing := emptyInClose.FindString(after)
+
+			if m == nil {
+				return "", fmt.Errorf("dal: parameter %s is an empty slice in an IN (...) list whose operand is not recognized", describeParam(param))
 			}
 
-			b.finalParams = append(b.finalParams, b.params[paramName])
+			before = before[:len(before)-len(m[0])] + b.getDialect().Bool(m[1] != "")
+			sql, pos = before+after[len(closing):], len(before)
+			continue
+		}
 
-			sParam++
+		placeholders := make([]string, len(values))
+		for j, v := range values {
+			b.finalParams = append(b.finalParams, v)
+			placeholders[j] = b.getDialect().Placeholder(len(b.finalParams))
 		}
 
-		sql = strings.Replace(sql, param, getPlaceHolder(iParam+sParam), 1)
+		before += strings.Join(placeholders, ", ")
+		sql, pos = before+after, len(before)
 	}
 
 	return sql, nil
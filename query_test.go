@@ -1,6 +1,7 @@
 package dal
 
 import (
+	"context"
 	"github.com/stretchr/testify/assert"
 	"strings"
 	"testing"
@@ -291,3 +292,31 @@ func TestQueryUpdate(t *testing.T) {
 	assert.Equal(t, []interface{}{"first", "second", int64(1)}, b.GetParameters())
 
 }
+
+func TestQueryTags(t *testing.T) {
+
+	b := NewBuilder()
+
+	b.Tag("route", "/users/:id").Tag("controller", "user's")
+	b.Select("column_1").From("table_1").Where("column_1 = :id").SetParameter("id", 1).Build()
+
+	assert.Equal(t, "SELECT column_1 FROM table_1 WHERE (column_1 = $1) /* controller='user%27s',route='%2Fusers%2F%3Aid' */", b.GetSQL())
+	assert.Equal(t, []interface{}{1}, b.GetParameters())
+
+	ctx := ContextWithTag(context.Background(), "traceparent", "00-abc-01")
+	ctx = ContextWithTag(ctx, "route", "ignored")
+
+	b = NewBuilder()
+
+	b.Tag("route", "/users").TagsFromContext(ctx)
+	b.Delete("table_1").Build()
+
+	assert.Equal(t, "DELETE FROM table_1  /* route='%2Fusers',traceparent='00-abc-01' */", b.GetSQL())
+
+	b = NewBuilder()
+
+	b.Tag("note", "*/ DROP TABLE x; --")
+	b.SQL("SELECT 1").Build()
+
+	assert.Equal(t, "SELECT 1 /* note='%2A%2F%20DROP%20TABLE%20x%3B%20--' */", b.GetSQL())
+}
@@ -0,0 +1,109 @@
+package dal
+
+import (
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+)
+
+// ReplicaPolicy chooses the replica that serves a read.
+type ReplicaPolicy int
+
+const (
+	// RoundRobin sends the reads to each replica in turn.
+	RoundRobin ReplicaPolicy = iota
+	// LeastConnections sends the reads to the replica with fewer connections in use.
+	LeastConnections
+)
+
+func parseReplicaPolicy(v string) (ReplicaPolicy, error) {
+	switch v {
+	case "", "round_robin":
+		return RoundRobin, nil
+	case "least_connections":
+		return LeastConnections, nil
+	}
+	return RoundRobin, fmt.Errorf("dal: unknown replica policy %q", v)
+}
+
+type replica struct {
+	db    *sql.DB
+	stmts *stmtCache
+}
+
+// AddReplica adds a read replica, the sessions send the statements of SelectBuilder to the replicas
+// while the writes and the transactions always go to the primary.
+func (c *Connection) AddReplica(db *sql.DB) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	r := &replica{db: db}
+	if c.stmts != nil {
+		r.stmts = newStmtCache(db, c.stmts.capacity)
+	}
+	c.replicas = append(c.replicas, r)
+}
+
+// SetReplicaPolicy sets how the replica serving a read is chosen.
+func (c *Connection) SetReplicaPolicy(p ReplicaPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.replicaPolicy = p
+}
+
+// pickReplica must be called with c.mu held.
+func (c *Connection) pickReplica() *replica {
+	switch len(c.replicas) {
+	case 0:
+		return nil
+	case 1:
+		return c.replicas[0]
+	}
+
+	if c.replicaPolicy == LeastConnections {
+		best := c.replicas[0]
+		inUse := best.db.Stats().InUse
+		for _, r := range c.replicas[1:] {
+			if n := r.db.Stats().InUse; n < inUse {
+				best, inUse = r, n
+			}
+		}
+		return best
+	}
+
+	n := atomic.AddUint64(&c.nextReplica, 1)
+	return c.replicas[(n-1)%uint64(len(c.replicas))]
+}
+
+// UsePrimary returns a copy of the session that sends every statement to the primary, for the reads
+// that must see the writes just done.
+func (s *Session) UsePrimary() *Session {
+	return &Session{Connection: s.Connection, handler: s.handler, primary: true}
+}
+
+func isReadOnly(b Builder) bool {
+	_, ok := b.b.(*SelectBuilder)
+	return ok
+}
+
+// handlerFor returns the handler of a replica for the read builders, the primary one otherwise.
+func (s *Session) handlerFor(b Builder) handlerConn {
+	if s.primary || !isReadOnly(b) {
+		return s.handler
+	}
+
+	return hookedConn{handlerConn: poolHandler{conn: s.Connection, read: true}, conn: s.Connection}
+}
+
+// pinnedHandlerFor is handlerFor keeping the same pool until release, so the statements of a call,
+// such as the COUNT and the rows of CountQuery, read the same replica.
+func (s *Session) pinnedHandlerFor(b Builder) (handlerConn, func()) {
+	if s.primary || !isReadOnly(b) {
+		return s.handler, func() {}
+	}
+
+	h, release := poolHandler{conn: s.Connection, read: true}.acquire()
+
+	return hookedConn{handlerConn: h, conn: s.Connection}, release
+}
@@ -0,0 +1,112 @@
+package dal
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplicaRouting(t *testing.T) {
+	primary := &fakeDB{}
+	replica1 := &fakeDB{}
+	replica2 := &fakeDB{}
+
+	conn := newFakeConnection(primary)
+	conn.AddReplica(sql.OpenDB(fakeConnector{replica1}))
+	conn.AddReplica(sql.OpenDB(fakeConnector{replica2}))
+
+	sess := conn.GetSession()
+
+	var sel Builder
+	sel.Select("id").From("test").Build()
+
+	var del Builder
+	del.Delete("test").Build()
+
+	for i := 0; i < 3; i++ {
+		_, err := sess.Query(sel)
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, sess.Exec(del))
+
+	_, err := sess.UsePrimary().Query(sel)
+	assert.NoError(t, err)
+
+	tx, err := conn.GetTransaction()
+	assert.NoError(t, err)
+	_, err = tx.Query(sel)
+	assert.NoError(t, err)
+	assert.NoError(t, tx.Commit())
+
+	assert.Equal(t, []string{"SELECT id FROM test", "SELECT id FROM test"}, replica1.Statements())
+	assert.Equal(t, []string{"SELECT id FROM test"}, replica2.Statements())
+	assert.Equal(t, []string{"DELETE FROM test ", "SELECT id FROM test", "BEGIN", "SELECT id FROM test", "COMMIT"}, primary.Statements())
+}
+
+func TestReplicaCountQuery(t *testing.T) {
+	replica1 := &fakeDB{query: fakeRowsResult([]string{"count"}, []driver.Value{int64(1)})}
+	replica2 := &fakeDB{query: fakeRowsResult([]string{"count"}, []driver.Value{int64(1)})}
+
+	conn := newFakeConnection(&fakeDB{})
+	conn.AddReplica(sql.OpenDB(fakeConnector{replica1}))
+	conn.AddReplica(sql.OpenDB(fakeConnector{replica2}))
+
+	sess := conn.GetSession()
+
+	var b Builder
+	sel := b.Select("id").From("test")
+	b.Build()
+
+	for i := 0; i < 2; i++ {
+		_, count, err := sess.CountQuery(*sel)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	}
+
+	// the COUNT and the rows of a call come from the same replica
+	assert.Equal(t, []string{"SELECT COUNT(1)  FROM test", "SELECT id FROM test"}, replica1.Statements())
+	assert.Equal(t, []string{"SELECT COUNT(1)  FROM test", "SELECT id FROM test"}, replica2.Statements())
+}
+
+func TestReplicaConcurrentChanges(t *testing.T) {
+	conn := newFakeConnection(&fakeDB{})
+	sess := conn.GetSession()
+
+	var sel Builder
+	sel.Select("id").From("test").Build()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 20; j++ {
+				_, err := sess.Query(sel)
+				assert.NoError(t, err)
+			}
+		}()
+	}
+
+	for i := 0; i < 4; i++ {
+		conn.AddReplica(sql.OpenDB(fakeConnector{&fakeDB{}}))
+		conn.SetReplicaPolicy(LeastConnections)
+		conn.SetStmtCacheSize(i)
+	}
+	wg.Wait()
+
+	assert.NoError(t, conn.Close())
+}
+
+func TestParseReplicaPolicy(t *testing.T) {
+	p, err := parseReplicaPolicy("least_connections")
+	assert.NoError(t, err)
+	assert.Equal(t, LeastConnections, p)
+
+	_, err = parseReplicaPolicy("random")
+	assert.Error(t, err)
+}
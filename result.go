@@ -0,0 +1,157 @@
+package dal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ExecResult summarizes the outcome of an INSERT, UPDATE or DELETE statement.
+type ExecResult struct {
+	rowsAffected int64
+	ids          []interface{}
+}
+
+// RowsAffected returns the number of rows matched by the statement.
+func (r *ExecResult) RowsAffected() int64 {
+	return r.rowsAffected
+}
+
+// LastInsertId returns the first value sent back by a RETURNING clause, or the id reported by the
+// driver for the dialects without one, nil if there is none.
+func (r *ExecResult) LastInsertId() interface{} {
+	if len(r.ids) == 0 {
+		return nil
+	}
+	return r.ids[0]
+}
+
+// Ids returns the first column of every row sent back by a RETURNING clause.
+func (r *ExecResult) Ids() []interface{} {
+	return r.ids
+}
+
+func isReturning(b Builder) bool {
+	ib, ok := b.b.(*InsertBuilder)
+	return ok && len(ib.returning) > 0
+}
+
+// returnsRows reports whether the statement sends back its RETURNING columns as rows, the dialects
+// without RETURNING only report the last insert id.
+func returnsRows(b Builder) bool {
+	if !isReturning(b) {
+		return false
+	}
+	_, _, ok := b.getDialect().Returning(b.b.(*InsertBuilder).returning)
+	return ok
+}
+
+func execResult(ctx context.Context, handler handlerConn, b Builder) (*ExecResult, error) {
+	if err := checkDialect(handler, b); err != nil {
+		return nil, err
+	}
+
+	if !returnsRows(b) {
+		ctx, after := observe(ctx, handler, b.GetSQL(), b.GetParameters(), b.timeout)
+
+		res, err := handler.ExecContext(ctx, b.GetSQL(), b.GetParameters()...)
+
+		after(affectedRows(res, err), err)
+
+		if err != nil {
+			return nil, wrapError(b.GetSQL(), err)
+		}
+
+		affected, err := res.RowsAffected()
+
+		if err != nil {
+			return nil, wrapError(b.GetSQL(), err)
+		}
+
+		result := &ExecResult{rowsAffected: affected}
+
+		if isReturning(b) {
+			id, err := res.LastInsertId()
+
+			if err != nil {
+				return nil, wrapError(b.GetSQL(), err)
+			}
+
+			result.ids = []interface{}{id}
+		}
+
+		return result, nil
+	}
+
+	cursor, err := iterate(ctx, handler, b)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer cursor.Close()
+
+	result := &ExecResult{ids: make([]interface{}, 0)}
+
+	for cursor.Next() {
+		values, err := cursor.ScanArray()
+
+		if err != nil {
+			return nil, wrapError(b.GetSQL(), err)
+		}
+
+		result.ids = append(result.ids, values[0])
+		result.rowsAffected++
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, wrapError(b.GetSQL(), err)
+	}
+
+	return result, nil
+}
+
+func insertReturning(ctx context.Context, handler handlerConn, b Builder, v ...interface{}) error {
+	if !isReturning(b) {
+		return errors.New("dal: insert needs a RETURNING clause, use InsertBuilder.Returning or LastInsertId")
+	}
+
+	if returnsRows(b) {
+		return scan(ctx, handler, b, v...)
+	}
+
+	if len(v) != 1 {
+		return fmt.Errorf("dal: %s only reports the last insert id, expected 1 destination, got %d", b.getDialect().Name(), len(v))
+	}
+
+	result, err := execResult(ctx, handler, b)
+
+	if err != nil {
+		return err
+	}
+
+	return assignID(v[0], result.LastInsertId().(int64))
+}
+
+// assignID stores the id reported by the driver in dest, a pointer to an integer or an interface{}.
+func assignID(dest interface{}, id int64) error {
+	v := reflect.ValueOf(dest)
+
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("dal: destination must be a non nil pointer, got %T", dest)
+	}
+
+	switch e := v.Elem(); e.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.SetInt(id)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		e.SetUint(uint64(id))
+	case reflect.Interface:
+		e.Set(reflect.ValueOf(id))
+	default:
+		return fmt.Errorf("dal: can not store the last insert id in %T", dest)
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package dal
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecResult(t *testing.T) {
+	f := &fakeDB{exec: func(string, []driver.Value) (driver.Result, error) {
+		return driver.RowsAffected(3), nil
+	}}
+
+	sess := newFakeConnection(f).GetSession()
+
+	var b Builder
+	b.Update("test").Set("active", "?").Where("id > ?").
+		SetParameter(0, false).SetParameter(1, 10).Build()
+
+	res, err := sess.ExecResult(b)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), res.RowsAffected())
+	assert.Nil(t, res.LastInsertId())
+}
+
+func TestExecResultReturning(t *testing.T) {
+	f := &fakeDB{query: fakeRowsResult([]string{"id"}, []driver.Value{int64(7)})}
+
+	sess := newFakeConnection(f).GetSession()
+
+	var b Builder
+	b.Insert("test").Columns("name").SetParameter(0, "daniel").LastInsertId().Build()
+
+	res, err := sess.ExecResult(b)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), res.RowsAffected())
+	assert.Equal(t, int64(7), res.LastInsertId())
+	assert.Equal(t, []interface{}{int64(7)}, res.Ids())
+}
+
+func TestInsertReturning(t *testing.T) {
+	f := &fakeDB{query: fakeRowsResult([]string{"id", "name"}, []driver.Value{int64(7), "daniel"})}
+
+	sess := newFakeConnection(f).GetSession()
+
+	var b Builder
+	b.Insert("test").Columns("name").SetParameter(0, "daniel").Returning("id", "name").Build()
+
+	assert.Equal(t, "INSERT INTO test(name) VALUES ($1) RETURNING id, name", b.GetSQL())
+
+	var id int64
+	var name string
+	err := sess.Insert(b, &id, &name)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), id)
+	assert.Equal(t, "daniel", name)
+
+	b = Builder{}
+	b.Insert("test").Columns("name").SetParameter(0, "daniel").Build()
+
+	assert.Error(t, sess.Insert(b, &id))
+}
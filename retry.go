@@ -0,0 +1,183 @@
+package dal
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"reflect"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// RetryPolicy describes how many times and how often a failed operation is attempted again.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, values lower than 2 disable retries.
+	MaxAttempts int
+	// Backoff returns the wait before the given attempt (starting at 1 for the first retry).
+	Backoff func(attempt int) time.Duration
+	// Retryable decides whether an error is worth another attempt.
+	Retryable func(err error) bool
+}
+
+// ExponentialBackoff doubles the wait on every attempt starting at base, without exceeding max.
+func ExponentialBackoff(base, max time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		d := base
+		for i := 1; i < attempt && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			d = max
+		}
+		return d
+	}
+}
+
+// WithJitter randomizes the wait returned by backoff between half and the whole of it, so clients
+// that failed together do not retry together.
+func WithJitter(backoff func(attempt int) time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		d := backoff(attempt)
+		if d <= 1 {
+			return d
+		}
+		return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+	}
+}
+
+// IsTransientError reports whether err is caused by a lost connection or a server shutting down,
+// in which case a new attempt may succeed on another connection.
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	// a statement cancelled by its context or its Timeout would run out of time again
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		// admin_shutdown, crash_shutdown, cannot_connect_now
+		case "57P01", "57P02", "57P03":
+			return true
+		}
+		// connection_exception class
+		return strings.HasPrefix(string(pqErr.Code), "08")
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return !netErr.Timeout()
+	}
+
+	return strings.Contains(err.Error(), "connection reset by peer")
+}
+
+// IsSerializationFailure reports whether err is a Postgres serialization failure (40001) or deadlock (40P01).
+func IsSerializationFailure(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+
+	return pqErr.Code == "40001" || pqErr.Code == "40P01"
+}
+
+func (p *RetryPolicy) retryable(err error, classify func(error) bool) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return classify(err)
+}
+
+func (p *RetryPolicy) wait(ctx context.Context, attempt int) error {
+	if p.Backoff == nil {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(p.Backoff(attempt))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// run calls fn until it succeeds, returns an error that is not retryable or the attempts are exhausted.
+// classify decides which errors are retryable when the policy does not set Retryable.
+func (p *RetryPolicy) run(ctx context.Context, classify func(error) bool, fn func() error) error {
+	err := fn()
+
+	if p == nil {
+		return err
+	}
+
+	for attempt := 1; err != nil && attempt < p.MaxAttempts && p.retryable(err, classify); attempt++ {
+		if werr := p.wait(ctx, attempt); werr != nil {
+			return err
+		}
+		err = fn()
+	}
+
+	return err
+}
+
+// SetRetryPolicy sets the policy used by the sessions of the connection to retry the reads that failed
+// with a transient error (see IsTransientError), retryExec extends it to Exec, ExecResult and Insert,
+// which is only safe for idempotent statements.
+func (c *Connection) SetRetryPolicy(p RetryPolicy, retryExec bool) {
+	c.readRetry = &p
+	c.retryWrites = retryExec
+}
+
+func (s *Session) retry(ctx context.Context, fn func() error) error {
+	return s.readRetry.run(ctx, IsTransientError, fn)
+}
+
+func (s *Session) retryExec(ctx context.Context, fn func() error) error {
+	if !s.retryWrites {
+		return fn()
+	}
+	return s.retry(ctx, fn)
+}
+
+// retryFor retries fn as a read when b is a select, and as an exec otherwise, so a statement writing
+// through Scan or Query is not run twice when the writes are not retried.
+func (s *Session) retryFor(ctx context.Context, b Builder, fn func() error) error {
+	if isReadOnly(b) {
+		return s.retry(ctx, fn)
+	}
+	return s.retryExec(ctx, fn)
+}
+
+// restoreTarget returns a function that truncates the slice pointed by o to its current length, so
+// the rows loaded by a failed attempt are not kept.
+func restoreTarget(o interface{}) func() {
+	v := reflect.ValueOf(o)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return func() {}
+	}
+
+	n := v.Elem().Len()
+
+	return func() {
+		v.Elem().SetLen(n)
+	}
+}
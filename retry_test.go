@@ -0,0 +1,106 @@
+package dal
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTransientError(t *testing.T) {
+	assert.True(t, IsTransientError(driver.ErrBadConn))
+	assert.True(t, IsTransientError(&QueryError{SQL: "SELECT 1", Err: &pq.Error{Code: "57P01"}}))
+	assert.True(t, IsTransientError(&pq.Error{Code: "08006"}))
+	assert.True(t, IsTransientError(errors.New("read tcp 10.0.0.1:5432: connection reset by peer")))
+	assert.False(t, IsTransientError(&pq.Error{Code: "23505"}))
+	assert.False(t, IsTransientError(nil))
+}
+
+func TestSessionRetry(t *testing.T) {
+	attempts := 0
+	f := &fakeDB{
+		query: func(q string, args []driver.Value) (driver.Rows, error) {
+			attempts++
+			if attempts == 1 {
+				return brokenStream(q, args)
+			}
+			return fakeRowsResult([]string{"id", "name"}, []driver.Value{int64(1), "daniel"}, []driver.Value{int64(2), "johan"})(q, args)
+		},
+		exec: func(string, []driver.Value) (driver.Result, error) {
+			attempts++
+			return nil, &pq.Error{Code: "57P01"}
+		},
+	}
+
+	conn := newFakeConnection(f)
+	conn.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, Backoff: WithJitter(ExponentialBackoff(time.Millisecond, 10*time.Millisecond))}, false)
+
+	sess := conn.GetSession()
+
+	var b Builder
+	b.Select("id", "name").From("test").Build()
+
+	var o []struct {
+		Id   int64
+		Name string
+	}
+	assert.NoError(t, sess.QueryType(b, &o))
+	assert.Equal(t, 2, attempts)
+	assert.Len(t, o, 2)
+
+	attempts = 0
+	b = Builder{}
+	b.Delete("test").Build()
+
+	assert.Error(t, sess.Exec(b))
+	assert.Equal(t, 1, attempts)
+
+	attempts = 0
+	f.query = func(q string, args []driver.Value) (driver.Rows, error) {
+		attempts++
+		return failedQuery(q, args)
+	}
+	b = Builder{}
+	b.Insert("test").Columns("name").SetParameter(0, "daniel").LastInsertId().Build()
+
+	var id int64
+	assert.Error(t, sess.Scan(b, &id))
+	assert.Equal(t, 1, attempts)
+
+	conn.SetRetryPolicy(RetryPolicy{MaxAttempts: 3}, true)
+
+	attempts = 0
+	assert.Error(t, sess.Exec(b))
+	assert.Equal(t, 3, attempts)
+}
+
+func TestSessionRetryTimeout(t *testing.T) {
+	f := &fakeDB{delay: time.Second, query: fakeRowsResult([]string{"id"}, []driver.Value{int64(1)})}
+	conn := newFakeConnection(f)
+	conn.SetRetryPolicy(RetryPolicy{MaxAttempts: 4}, false)
+
+	var b Builder
+	b.Timeout(50 * time.Millisecond).Select("id").From("test").Build()
+
+	// a statement that ran out of time is not run again
+	start := time.Now()
+	_, err := conn.GetSession().Query(b)
+
+	assert.True(t, errors.Is(err, ErrTimeout), "unexpected error %v", err)
+	assert.Less(t, int64(time.Since(start)), int64(150*time.Millisecond))
+	assert.False(t, IsTransientError(context.DeadlineExceeded))
+	assert.False(t, IsTransientError(context.Canceled))
+}
+
+func TestWithJitter(t *testing.T) {
+	backoff := WithJitter(func(int) time.Duration { return 100 * time.Millisecond })
+
+	for i := 0; i < 20; i++ {
+		d := backoff(1)
+		assert.True(t, d >= 50*time.Millisecond && d <= 100*time.Millisecond, d)
+	}
+}
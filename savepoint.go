@@ -0,0 +1,98 @@
+package dal
+
+import (
+	"context"
+	"strconv"
+)
+
+// Savepoint establishes a new savepoint with the given name inside the transaction.
+func (tx *Transaction) Savepoint(name string) error {
+	d := tx.dialect()
+	if d.Name() == SQLServer.Name() {
+		return tx.execSavepoint("SAVE TRANSACTION " + d.QuoteIdent(name))
+	}
+	return tx.execSavepoint("SAVEPOINT " + d.QuoteIdent(name))
+}
+
+// RollbackTo undoes everything done after the savepoint was established, the savepoint remains valid.
+func (tx *Transaction) RollbackTo(name string) error {
+	d := tx.dialect()
+	if d.Name() == SQLServer.Name() {
+		return tx.execSavepoint("ROLLBACK TRANSACTION " + d.QuoteIdent(name))
+	}
+	return tx.execSavepoint("ROLLBACK TO SAVEPOINT " + d.QuoteIdent(name))
+}
+
+// Release destroys the savepoint keeping the work done after it was established. SQL Server can not
+// release a savepoint, its savepoints last until the transaction ends.
+func (tx *Transaction) Release(name string) error {
+	d := tx.dialect()
+	if d.Name() == SQLServer.Name() {
+		return nil
+	}
+	return tx.execSavepoint("RELEASE SAVEPOINT " + d.QuoteIdent(name))
+}
+
+// dialect returns the dialect of the connection the transaction was started on.
+func (tx *Transaction) dialect() Dialect {
+	if h, ok := tx.handler.(hookedConn); ok {
+		return h.conn.Dialect()
+	}
+	return Postgres
+}
+
+// Begin starts a nested transaction backed by a savepoint. Commit on the child releases the savepoint
+// and Rollback undoes only the work done by the child, the outer transaction stays usable.
+func (tx *Transaction) Begin() (*Transaction, error) {
+	root := tx
+	for root.parent != nil {
+		root = root.parent
+	}
+	root.nested++
+
+	child := &Transaction{
+		tx:        tx.tx,
+		handler:   tx.handler,
+		parent:    tx,
+		savepoint: "dal_savepoint_" + strconv.Itoa(root.nested),
+		readOnly:  tx.readOnly,
+	}
+
+	if err := tx.Savepoint(child.savepoint); err != nil {
+		return nil, err
+	}
+
+	return child, nil
+}
+
+func (tx *Transaction) releaseNested() error {
+	if tx.done {
+		return nil
+	}
+	tx.done = true
+
+	return tx.parent.Release(tx.savepoint)
+}
+
+func (tx *Transaction) rollbackNested() error {
+	if tx.done {
+		return nil
+	}
+	tx.done = true
+
+	if err := tx.parent.RollbackTo(tx.savepoint); err != nil {
+		return err
+	}
+
+	return tx.parent.Release(tx.savepoint)
+}
+
+func (tx *Transaction) execSavepoint(sql string) error {
+	ctx, after := observe(context.Background(), tx.handler, sql, nil, 0)
+
+	res, err := tx.tx.ExecContext(ctx, sql)
+
+	after(affectedRows(res, err), err)
+
+	return wrapError(sql, err)
+}
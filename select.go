@@ -1,7 +1,5 @@
 package dal
 
-import "strconv"
-
 /**
 SELECT Section
  */
@@ -56,12 +54,14 @@ func (b *SelectBuilder) RightJoin(join Join) *SelectBuilder {
 	return b.addJoin(right, join)
 }
 
-func (b *SelectBuilder) Where(condition string) *SelectBuilder {
-	return b.addWhere("AND", condition)
+//Where - adds a condition given as a string or a Cond, joined with AND
+func (b *SelectBuilder) Where(condition interface{}) *SelectBuilder {
+	return b.addWhere("AND", b.b.condition(condition))
 }
 
-func (b *SelectBuilder) OrWhere(condition string) *SelectBuilder {
-	return b.addWhere("OR", condition)
+//OrWhere - adds a condition given as a string or a Cond, joined with OR
+func (b *SelectBuilder) OrWhere(condition interface{}) *SelectBuilder {
+	return b.addWhere("OR", b.b.condition(condition))
 }
 
 func (b *SelectBuilder) GroupBy(columns ...string) *SelectBuilder {
@@ -82,14 +82,15 @@ func (b *SelectBuilder) GroupBy(columns ...string) *SelectBuilder {
 	return b
 }
 
-func (b *SelectBuilder) Having(condition string) *SelectBuilder {
+//Having - sets the condition, given as a string or a Cond, on the groups
+func (b *SelectBuilder) Having(condition interface{}) *SelectBuilder {
 	p := partSQL{part: havingPartEnum}
 
 	if ok, _ := b.b.getPart(havingPartEnum); ok {
 		b.b.removePart(havingPartEnum)
 	}
 
-	p.parts = []string{condition}
+	p.parts = []string{b.b.condition(condition)}
 	b.b.sqlParts = append(b.b.sqlParts, p)
 
 	return b
@@ -160,10 +161,11 @@ func (b *SelectBuilder) GetSQL() (q string) {
 	}
 
 	if b.isLimitQuery() {
-		q += " LIMIT " + strconv.Itoa(int(b.maxResults))
+		var offset int64
 		if b.firstResult > 0 {
-			q += " OFFSET " + strconv.Itoa(int(b.firstResult) - 1)
+			offset = b.firstResult - 1
 		}
+		q += b.b.getDialect().LimitOffset(b.maxResults, offset)
 	}
 
 	return
@@ -201,6 +203,8 @@ func (b *SelectBuilder) GetCountSQL() (q string) {
 
 	q, _ = b.b.build(q)
 
+	q = b.b.annotate(b.b.quoteIdents(q))
+
 	return
 }
 
@@ -0,0 +1,305 @@
+package dal
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// StmtCacheStats reports the usage of the prepared statement cache of a Connection.
+type StmtCacheStats struct {
+	Size      int
+	Capacity  int
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+type stmtEntry struct {
+	sql     string
+	stmt    *sql.Stmt
+	refs    int
+	evicted bool
+}
+
+// stmtCache keeps the most recently used prepared statements, keyed by their SQL. An entry is
+// referenced while a statement runs on it, an evicted entry is closed once it is released.
+type stmtCache struct {
+	sync.Mutex
+	db       *sql.DB
+	capacity int
+	order    *list.List
+	entries  map[string]*list.Element
+	stats    StmtCacheStats
+	// closed is set once the cache is dropped, the transactions still using it no longer keep statements
+	closed bool
+}
+
+func newStmtCache(db *sql.DB, capacity int) *stmtCache {
+	return &stmtCache{
+		db:       db,
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// get returns the entry of query, preparing it on a miss, it must be given back to release.
+func (c *stmtCache) get(ctx context.Context, query string) (*stmtEntry, error) {
+	c.Lock()
+	if e, ok := c.entries[query]; ok {
+		c.order.MoveToFront(e)
+		c.stats.Hits++
+		entry := e.Value.(*stmtEntry)
+		entry.refs++
+		c.Unlock()
+		return entry, nil
+	}
+	c.stats.Misses++
+	c.Unlock()
+
+	stmt, err := c.db.PrepareContext(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	// another goroutine may have prepared the same statement meanwhile
+	if e, ok := c.entries[query]; ok {
+		stmt.Close()
+		c.order.MoveToFront(e)
+		entry := e.Value.(*stmtEntry)
+		entry.refs++
+		return entry, nil
+	}
+
+	entry := &stmtEntry{sql: query, stmt: stmt, refs: 1}
+
+	if c.closed {
+		entry.evicted = true
+		return entry, nil
+	}
+
+	c.entries[query] = c.order.PushFront(entry)
+
+	for c.order.Len() > c.capacity {
+		last := c.order.Back()
+		c.order.Remove(last)
+		c.evict(last.Value.(*stmtEntry))
+		c.stats.Evictions++
+	}
+
+	return entry, nil
+}
+
+// release gives back an entry returned by get, closing it when it was evicted meanwhile.
+func (c *stmtCache) release(entry *stmtEntry) {
+	c.Lock()
+	defer c.Unlock()
+
+	entry.refs--
+	if entry.evicted && entry.refs == 0 {
+		entry.stmt.Close()
+	}
+}
+
+// evict removes the entry from the cache, it is closed now or by its last release.
+func (c *stmtCache) evict(entry *stmtEntry) {
+	delete(c.entries, entry.sql)
+	entry.evicted = true
+	if entry.refs == 0 {
+		entry.stmt.Close()
+	}
+}
+
+func (c *stmtCache) getStats() StmtCacheStats {
+	c.Lock()
+	defer c.Unlock()
+
+	s := c.stats
+	s.Size = c.order.Len()
+	s.Capacity = c.capacity
+
+	return s
+}
+
+func (c *stmtCache) close() {
+	c.Lock()
+	defer c.Unlock()
+
+	for _, e := range c.entries {
+		c.evict(e.Value.(*stmtEntry))
+	}
+	c.order.Init()
+	c.closed = true
+}
+
+// stmtHandler runs every statement through the cache, binding it to tx when it is not nil.
+type stmtHandler struct {
+	cache *stmtCache
+	tx    *sql.Tx
+}
+
+// conn is what runs the statements that are not cached.
+func (h stmtHandler) conn() handlerConn {
+	if h.tx != nil {
+		return h.tx
+	}
+	return h.cache.db
+}
+
+func (h stmtHandler) stmt(ctx context.Context, query string) (*sql.Stmt, func(), error) {
+	entry, err := h.cache.get(ctx, query)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	release := func() { h.cache.release(entry) }
+
+	if h.tx == nil {
+		return entry.stmt, release, nil
+	}
+
+	return h.tx.StmtContext(ctx, entry.stmt), release, nil
+}
+
+func (h stmtHandler) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if annotated(query) {
+		return h.conn().ExecContext(ctx, query, args...)
+	}
+
+	stmt, release, err := h.stmt(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return stmt.ExecContext(ctx, args...)
+}
+
+func (h stmtHandler) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if annotated(query) {
+		return h.conn().QueryContext(ctx, query, args...)
+	}
+
+	stmt, release, err := h.stmt(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+	// the rows keep the statement open until they are closed, even when it is evicted meanwhile
+	defer release()
+
+	return stmt.QueryContext(ctx, args...)
+}
+
+func (h stmtHandler) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if annotated(query) {
+		return h.conn().QueryRowContext(ctx, query, args...)
+	}
+
+	stmt, release, err := h.stmt(ctx, query)
+
+	if err != nil {
+		// let database/sql build the row so the prepare error is returned by Scan
+		return h.conn().QueryRowContext(ctx, query, args...)
+	}
+	defer release()
+
+	return stmt.QueryRowContext(ctx, args...)
+}
+
+// SetStmtCacheSize enables a cache of up to size prepared statements shared by the sessions and
+// transactions of the connection, a size of 0 disables it and closes the cached statements. The
+// statements carrying tags are run without being cached.
+func (c *Connection) SetStmtCacheSize(size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stmts != nil {
+		c.stmts.close()
+		c.stmts = nil
+	}
+
+	if size > 0 {
+		c.stmts = newStmtCache(c.db, size)
+	}
+
+	for _, r := range c.replicas {
+		if r.stmts != nil {
+			r.stmts.close()
+			r.stmts = nil
+		}
+		if size > 0 {
+			r.stmts = newStmtCache(r.db, size)
+		}
+	}
+}
+
+// StmtCacheStats returns the statistics of the prepared statement cache.
+func (c *Connection) StmtCacheStats() StmtCacheStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.stmts == nil {
+		return StmtCacheStats{}
+	}
+	return c.stmts.getStats()
+}
+
+// handler returns the handler of a transaction bound to tx and stmts, or the one of the sessions when
+// tx is nil, which runs each statement on the pool in use at that time.
+func (c *Connection) handler(tx *sql.Tx, stmts *stmtCache, span Span) handlerConn {
+	var h handlerConn = poolHandler{conn: c}
+	if tx != nil {
+		h = tx
+		if stmts != nil {
+			h = stmtHandler{cache: stmts, tx: tx}
+		}
+	}
+	return hookedConn{handlerConn: h, conn: c, tx: tx, span: span}
+}
+
+// poolHandler runs every statement on the pools the connection has when it starts, a replica for
+// the reads when there are.
+type poolHandler struct {
+	conn *Connection
+	read bool
+}
+
+func (h poolHandler) acquire() (handlerConn, func()) {
+	db, stmts, release := h.conn.acquire(h.read)
+
+	if stmts != nil {
+		return stmtHandler{cache: stmts}, release
+	}
+	return db, release
+}
+
+func (h poolHandler) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	handler, release := h.acquire()
+	defer release()
+
+	return handler.ExecContext(ctx, query, args...)
+}
+
+func (h poolHandler) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	handler, release := h.acquire()
+	// the rows hold their connection, which a closed pool only closes when they are
+	defer release()
+
+	return handler.QueryContext(ctx, query, args...)
+}
+
+func (h poolHandler) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	handler, release := h.acquire()
+	defer release()
+
+	return handler.QueryRowContext(ctx, query, args...)
+}
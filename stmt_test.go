@@ -0,0 +1,108 @@
+package dal
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStmtCache(t *testing.T) {
+	f := &fakeDB{query: fakeRowsResult([]string{"id"}, []driver.Value{int64(1)})}
+	conn := newFakeConnection(f)
+	conn.SetStmtCacheSize(2)
+
+	sess := conn.GetSession()
+
+	query := func(table string) {
+		var b Builder
+		b.Select("id").From(table).Where("id = ?").SetParameter(0, 1).Build()
+
+		_, err := sess.Query(b)
+		assert.NoError(t, err)
+	}
+
+	query("table_1")
+	query("table_1")
+	query("table_2")
+	query("table_3")
+	query("table_1")
+
+	assert.Equal(t, StmtCacheStats{Size: 2, Capacity: 2, Hits: 1, Misses: 4, Evictions: 2}, conn.StmtCacheStats())
+
+	tx, err := conn.GetTransaction()
+	assert.NoError(t, err)
+
+	var b Builder
+	b.Select("id").From("table_1").Where("id = ?").SetParameter(0, 1).Build()
+
+	var id int64
+	assert.NoError(t, tx.Scan(b, &id))
+	assert.Equal(t, int64(1), id)
+	assert.NoError(t, tx.Commit())
+
+	assert.Equal(t, uint64(2), conn.StmtCacheStats().Hits)
+
+	conn.SetStmtCacheSize(0)
+	assert.Equal(t, StmtCacheStats{}, conn.StmtCacheStats())
+}
+
+func TestStmtCacheConcurrentEviction(t *testing.T) {
+	f := &fakeDB{query: fakeRowsResult([]string{"id"}, []driver.Value{int64(1)}), delay: time.Millisecond}
+	conn := newFakeConnection(f)
+	conn.SetStmtCacheSize(1)
+
+	sess := conn.GetSession()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			for j := 0; j < 20; j++ {
+				var b Builder
+				b.Select("id").From(fmt.Sprintf("table_%d", (i+j)%4)).Where("id = ?").SetParameter(0, 1).Build()
+
+				var id int64
+				assert.NoError(t, sess.Scan(b, &id))
+
+				_, err := sess.Query(b)
+				assert.NoError(t, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	stats := conn.StmtCacheStats()
+	assert.Equal(t, 1, stats.Size)
+	assert.Equal(t, uint64(320), stats.Hits+stats.Misses)
+}
+
+func TestStmtCacheTaggedStatements(t *testing.T) {
+	f := &fakeDB{query: fakeRowsResult([]string{"id"}, []driver.Value{int64(1)})}
+	conn := newFakeConnection(f)
+	conn.SetStmtCacheSize(1)
+
+	sess := conn.GetSession()
+
+	query := func(ctx context.Context) {
+		var b Builder
+		b.TagsFromContext(ctx).Select("id").From("test").Where("id = ?").SetParameter(0, 1).Build()
+
+		var id int64
+		assert.NoError(t, sess.Scan(b, &id))
+	}
+
+	query(context.Background())
+	query(ContextWithTag(context.Background(), "request", "1"))
+	query(ContextWithTag(context.Background(), "request", "2"))
+	query(context.Background())
+
+	assert.Equal(t, StmtCacheStats{Size: 1, Capacity: 1, Hits: 1, Misses: 1}, conn.StmtCacheStats())
+	assert.Contains(t, f.Statements(), "SELECT id FROM test WHERE (id = $1) /* request='2' */")
+}
@@ -0,0 +1,66 @@
+package dal
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ErrTimeout matches, with errors.Is, the errors of statements cancelled because they ran out of time,
+// either by the context deadline or by the server statement_timeout.
+var ErrTimeout = errors.New("dal: statement timeout")
+
+// Timeout - maximum time the statement may run, enforced with a context deadline and, inside a Postgres
+// transaction, with SET LOCAL statement_timeout
+func (b *Builder) Timeout(d time.Duration) *Builder {
+	b.timeout = d
+
+	return b
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var pqErr *pq.Error
+	// 57014 query_canceled is raised by statement_timeout
+	return errors.As(err, &pqErr) && pqErr.Code == "57014"
+}
+
+func noopRelease(error) {}
+
+func (h hookedConn) setTimeout(ctx context.Context, timeout time.Duration) (context.Context, func(err error)) {
+	if timeout <= 0 {
+		return ctx, noopRelease
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+
+	// SET LOCAL statement_timeout only exists on Postgres
+	if h.tx == nil || h.conn.Dialect().Name() != Postgres.Name() {
+		return ctx, func(error) {
+			cancel()
+		}
+	}
+
+	ms := strconv.FormatInt(int64(timeout/time.Millisecond), 10)
+	_, setErr := h.tx.ExecContext(ctx, "SET LOCAL statement_timeout = "+ms)
+
+	return ctx, func(err error) {
+		cancel()
+		if setErr == nil && !abortsTransaction(err) {
+			h.tx.ExecContext(context.Background(), "SET LOCAL statement_timeout TO DEFAULT")
+		}
+	}
+}
+
+// abortsTransaction reports whether err comes from the server, which aborts a Postgres transaction so no
+// other statement can run in it. The errors found by dal, such as sql.ErrNoRows, leave it usable.
+func abortsTransaction(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) || isTimeout(err)
+}
@@ -0,0 +1,82 @@
+package dal
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeout(t *testing.T) {
+	f := &fakeDB{delay: 100 * time.Millisecond, query: fakeRowsResult([]string{"id"}, []driver.Value{int64(1)})}
+	conn := newFakeConnection(f)
+
+	var b Builder
+	b.Timeout(10 * time.Millisecond).Select("id").From("test").Build()
+
+	_, err := conn.GetSession().Query(b)
+
+	assert.True(t, errors.Is(err, ErrTimeout), "unexpected error %v", err)
+
+	b = Builder{}
+	b.Timeout(time.Second).Select("id").From("test").Build()
+
+	_, err = conn.GetSession().Query(b)
+
+	assert.NoError(t, err)
+	assert.False(t, errors.Is(err, ErrTimeout))
+}
+
+func TestTimeoutTransaction(t *testing.T) {
+	f := &fakeDB{}
+	conn := newFakeConnection(f)
+
+	tx, err := conn.GetTransaction()
+	assert.NoError(t, err)
+
+	var b Builder
+	b.Timeout(1500 * time.Millisecond).Delete("test").Build()
+
+	assert.NoError(t, tx.Exec(b))
+	assert.NoError(t, tx.Commit())
+
+	assert.Equal(t, []string{
+		"BEGIN",
+		"SET LOCAL statement_timeout = 1500",
+		"DELETE FROM test ",
+		"SET LOCAL statement_timeout TO DEFAULT",
+		"COMMIT"}, f.Statements())
+}
+
+func TestTimeoutTransactionNoRows(t *testing.T) {
+	f := &fakeDB{query: fakeRowsResult([]string{"id"})}
+	conn := newFakeConnection(f)
+
+	tx, err := conn.GetTransaction()
+	assert.NoError(t, err)
+
+	var b Builder
+	b.Timeout(500 * time.Millisecond).Select("id").From("t").Build()
+
+	var id int64
+	assert.Equal(t, sql.ErrNoRows, tx.Scan(b, &id))
+
+	b = Builder{}
+	b.Select("id").From("big").Build()
+
+	_, err = tx.Query(b)
+	assert.NoError(t, err)
+	assert.NoError(t, tx.Commit())
+
+	// the statement found no row but did not fail, the timeout is reset for the next ones
+	assert.Equal(t, []string{
+		"BEGIN",
+		"SET LOCAL statement_timeout = 500",
+		"SELECT id FROM t",
+		"SET LOCAL statement_timeout TO DEFAULT",
+		"SELECT id FROM big",
+		"COMMIT"}, f.Statements())
+}
@@ -0,0 +1,87 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Tracer opens the spans of transactions and statements, it is meant to be implemented by an adapter
+// of the tracing library in use.
+type Tracer interface {
+	// StartSpan starts a span that is a child of the span found in ctx, if any.
+	StartSpan(ctx context.Context, name string) (context.Context, Span)
+	// ContextWithSpan returns a copy of ctx carrying span as the current span.
+	ContextWithSpan(ctx context.Context, span Span) context.Context
+}
+
+// Span is a unit of work opened by a Tracer.
+type Span interface {
+	SetAttribute(key string, value interface{})
+	// End finishes the span, err is the error that made the operation fail, if any.
+	End(err error)
+}
+
+// SetTracer sets the tracer used for the transactions and statements run through the connection.
+func (c *Connection) SetTracer(t Tracer) {
+	c.tracer = t
+}
+
+func (c *Connection) getTracer() Tracer {
+	if c.tracer == nil && c.manager != nil {
+		return c.manager.tracer
+	}
+	return c.tracer
+}
+
+func (c *Connection) startSpan(ctx context.Context, name string) (context.Context, Span) {
+	tracer := c.getTracer()
+	if tracer == nil {
+		return ctx, nil
+	}
+
+	ctx, span := tracer.StartSpan(ctx, name)
+	span.SetAttribute("db.system", c.Dialect().Name())
+	span.SetAttribute("db.connection", c.name)
+
+	return ctx, span
+}
+
+// startStatementSpan opens the span of a statement, as a child of the transaction span when there is one.
+func (h hookedConn) startStatementSpan(ctx context.Context, sql string) (context.Context, Span) {
+	tracer := h.conn.getTracer()
+	if tracer == nil {
+		return ctx, nil
+	}
+
+	if h.span != nil {
+		ctx = tracer.ContextWithSpan(ctx, h.span)
+	}
+
+	ctx, span := h.conn.startSpan(ctx, "dal.query")
+	span.SetAttribute("db.statement", NormalizeSQL(sql))
+
+	return ctx, span
+}
+
+func endSpan(span Span, err error) {
+	if span != nil {
+		span.End(err)
+	}
+}
+
+func (tx *Transaction) endSpan(rollback bool, err error) {
+	if tx.span == nil {
+		return
+	}
+
+	if rollback {
+		tx.span.SetAttribute("db.rollback", true)
+	}
+
+	if err == sql.ErrTxDone {
+		err = nil
+	}
+
+	tx.span.End(err)
+	tx.span = nil
+}
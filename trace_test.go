@@ -0,0 +1,98 @@
+package dal
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type spanKey struct{}
+
+type fakeSpan struct {
+	name   string
+	parent *fakeSpan
+	attrs  map[string]interface{}
+	ended  bool
+	err    error
+}
+
+func (s *fakeSpan) SetAttribute(key string, value interface{}) {
+	s.attrs[key] = value
+}
+
+func (s *fakeSpan) End(err error) {
+	s.ended = true
+	s.err = err
+}
+
+type fakeTracer struct {
+	spans []*fakeSpan
+}
+
+func (t *fakeTracer) StartSpan(ctx context.Context, name string) (context.Context, Span) {
+	parent, _ := ctx.Value(spanKey{}).(*fakeSpan)
+	s := &fakeSpan{name: name, parent: parent, attrs: make(map[string]interface{})}
+	t.spans = append(t.spans, s)
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+func (t *fakeTracer) ContextWithSpan(ctx context.Context, span Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+func TestTracer(t *testing.T) {
+	f := &fakeDB{exec: func(q string, args []driver.Value) (driver.Result, error) {
+		if len(args) > 0 && args[0] == int64(2) {
+			return nil, errors.New("boom")
+		}
+		return driver.RowsAffected(1), nil
+	}}
+
+	tracer := &fakeTracer{}
+	conn := newFakeConnection(f)
+	conn.name = "main"
+	conn.SetTracer(tracer)
+
+	ctx, root := tracer.StartSpan(context.Background(), "request")
+
+	tx, err := conn.GetTransactionContext(ctx, nil)
+	assert.NoError(t, err)
+
+	for i := 1; i <= 2; i++ {
+		var b Builder
+		b.Delete("test").Where("id = ?").SetParameter(0, i).Build()
+		tx.ExecContext(context.Background(), b)
+	}
+
+	assert.NoError(t, tx.Rollback())
+
+	sess := conn.GetSession()
+
+	var b Builder
+	b.Delete("test").Where("id = 10").Build()
+	assert.NoError(t, sess.ExecContext(ctx, b))
+
+	assert.Len(t, tracer.spans, 5)
+
+	txSpan := tracer.spans[1]
+	assert.Equal(t, "dal.transaction", txSpan.name)
+	assert.Equal(t, root, txSpan.parent)
+	assert.True(t, txSpan.ended)
+	assert.Equal(t, true, txSpan.attrs["db.rollback"])
+
+	for _, s := range tracer.spans[2:4] {
+		assert.Equal(t, "dal.query", s.name)
+		assert.Equal(t, txSpan, s.parent)
+		assert.Equal(t, "DELETE FROM test WHERE (id = ?)", s.attrs["db.statement"])
+		assert.Equal(t, "main", s.attrs["db.connection"])
+		assert.True(t, s.ended)
+	}
+	assert.Equal(t, int64(1), tracer.spans[2].attrs["db.rows_affected"])
+	assert.EqualError(t, tracer.spans[3].err, "boom")
+
+	assert.Equal(t, root, tracer.spans[4].parent)
+	assert.NoError(t, tracer.spans[4].err)
+}
@@ -0,0 +1,72 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+)
+
+// TxOptions holds the settings used to start a transaction.
+type TxOptions struct {
+	// Isolation is the isolation level, the zero value uses the server default.
+	Isolation sql.IsolationLevel
+	// ReadOnly starts a READ ONLY transaction, writes are rejected before reaching the server.
+	ReadOnly bool
+	// Deferrable sets the Postgres DEFERRABLE mode, only meaningful for SERIALIZABLE READ ONLY transactions.
+	Deferrable bool
+}
+
+func (o *TxOptions) txOptions() *sql.TxOptions {
+	if o == nil {
+		return nil
+	}
+	return &sql.TxOptions{Isolation: o.Isolation, ReadOnly: o.ReadOnly}
+}
+
+func (t *Transaction) checkWritable(b Builder) error {
+	if !t.readOnly {
+		return nil
+	}
+
+	switch b.b.(type) {
+	case *InsertBuilder, *UpdateBuilder, *DeleteBuilder:
+		return ErrReadOnlyTransaction
+	}
+
+	return nil
+}
+
+// SetTransactionRetry sets the policy used by RunInTransaction to retry serialization failures and deadlocks.
+func (c *Connection) SetTransactionRetry(p RetryPolicy) {
+	c.txRetry = &p
+}
+
+// RunInTransaction runs fn inside a transaction that is committed when fn returns nil and rolled back
+// when it returns an error or panics, in which case the panic is raised again after the rollback.
+// The whole function is attempted again following the policy set with SetTransactionRetry.
+func (c *Connection) RunInTransaction(ctx context.Context, opts *TxOptions, fn func(tx *Transaction) error) error {
+	return c.txRetry.run(ctx, IsSerializationFailure, func() error {
+		return c.runInTransaction(ctx, opts, fn)
+	})
+}
+
+func (c *Connection) runInTransaction(ctx context.Context, opts *TxOptions, fn func(tx *Transaction) error) (err error) {
+	tx, err := c.GetTransactionContext(ctx, opts)
+
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err = fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
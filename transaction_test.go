@@ -0,0 +1,174 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunInTransactionCommit(t *testing.T) {
+	f := &fakeDB{}
+	conn := newFakeConnection(f)
+
+	var b Builder
+	b.Delete("test").Build()
+
+	err := conn.RunInTransaction(context.Background(), nil, func(tx *Transaction) error {
+		return tx.Exec(b)
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"BEGIN", "DELETE FROM test ", "COMMIT"}, f.Statements())
+}
+
+func TestRunInTransactionRollback(t *testing.T) {
+	f := &fakeDB{}
+	conn := newFakeConnection(f)
+
+	fail := errors.New("fail")
+
+	err := conn.RunInTransaction(context.Background(), nil, func(tx *Transaction) error {
+		return fail
+	})
+
+	assert.Equal(t, fail, err)
+	assert.Equal(t, []string{"BEGIN", "ROLLBACK"}, f.Statements())
+
+	f = &fakeDB{}
+	conn = newFakeConnection(f)
+
+	assert.PanicsWithValue(t, "boom", func() {
+		conn.RunInTransaction(context.Background(), nil, func(tx *Transaction) error {
+			panic("boom")
+		})
+	})
+	assert.Equal(t, []string{"BEGIN", "ROLLBACK"}, f.Statements())
+}
+
+func TestRunInTransactionRetry(t *testing.T) {
+	attempts := 0
+	f := &fakeDB{exec: func(string, []driver.Value) (driver.Result, error) {
+		attempts++
+		if attempts < 3 {
+			return nil, &pq.Error{Code: "40001"}
+		}
+		return driver.RowsAffected(1), nil
+	}}
+	conn := newFakeConnection(f)
+	conn.SetTransactionRetry(RetryPolicy{MaxAttempts: 3, Backoff: ExponentialBackoff(time.Millisecond, 5*time.Millisecond)})
+
+	var b Builder
+	b.Delete("test").Build()
+
+	err := conn.RunInTransaction(context.Background(), nil, func(tx *Transaction) error {
+		return tx.Exec(b)
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, []string{
+		"BEGIN", "DELETE FROM test ", "ROLLBACK",
+		"BEGIN", "DELETE FROM test ", "ROLLBACK",
+		"BEGIN", "DELETE FROM test ", "COMMIT"}, f.Statements())
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond)
+
+	assert.Equal(t, 10*time.Millisecond, backoff(1))
+	assert.Equal(t, 20*time.Millisecond, backoff(2))
+	assert.Equal(t, 40*time.Millisecond, backoff(3))
+	assert.Equal(t, 50*time.Millisecond, backoff(4))
+}
+
+func TestNestedTransaction(t *testing.T) {
+	f := &fakeDB{}
+	conn := newFakeConnection(f)
+
+	tx, err := conn.GetTransaction()
+	assert.NoError(t, err)
+
+	child, err := tx.Begin()
+	assert.NoError(t, err)
+	assert.NoError(t, child.Commit())
+
+	child, err = tx.Begin()
+	assert.NoError(t, err)
+	assert.NoError(t, child.Rollback())
+	assert.NoError(t, child.Rollback())
+
+	assert.NoError(t, tx.Savepoint(`my"point`))
+	assert.NoError(t, tx.RollbackTo(`my"point`))
+	assert.NoError(t, tx.Commit())
+
+	assert.Equal(t, []string{
+		"BEGIN",
+		`SAVEPOINT "dal_savepoint_1"`,
+		`RELEASE SAVEPOINT "dal_savepoint_1"`,
+		`SAVEPOINT "dal_savepoint_2"`,
+		`ROLLBACK TO SAVEPOINT "dal_savepoint_2"`,
+		`RELEASE SAVEPOINT "dal_savepoint_2"`,
+		`SAVEPOINT "my""point"`,
+		`ROLLBACK TO SAVEPOINT "my""point"`,
+		"COMMIT"}, f.Statements())
+}
+
+func TestReadOnlyTransaction(t *testing.T) {
+	f := &fakeDB{}
+	conn := newFakeConnection(f)
+
+	tx, err := conn.GetTransaction(TxOptions{Isolation: sql.LevelSerializable, ReadOnly: true, Deferrable: true})
+	assert.NoError(t, err)
+	assert.True(t, tx.IsReadOnly())
+
+	var b Builder
+	b.Delete("test").Build()
+
+	assert.Equal(t, ErrReadOnlyTransaction, tx.Exec(b))
+
+	child, err := tx.Begin()
+	assert.NoError(t, err)
+	assert.True(t, child.IsReadOnly())
+
+	b = Builder{}
+	b.Insert("test").Columns("name").SetParameter(0, "daniel").LastInsertId().Build()
+
+	_, err = child.ExecResult(b)
+	assert.Equal(t, ErrReadOnlyTransaction, err)
+	assert.Equal(t, ErrReadOnlyTransaction, child.Insert(b))
+
+	var id int64
+	assert.Equal(t, ErrReadOnlyTransaction, child.Scan(b, &id))
+	_, err = child.Query(b)
+	assert.Equal(t, ErrReadOnlyTransaction, err)
+	_, err = child.QueryArray(b)
+	assert.Equal(t, ErrReadOnlyTransaction, err)
+	assert.Equal(t, ErrReadOnlyTransaction, child.QueryType(b, &[]struct{ ID int64 }{}))
+	_, err = child.FirstResult(b)
+	assert.Equal(t, ErrReadOnlyTransaction, err)
+	_, err = child.FirstResultArray(b)
+	assert.Equal(t, ErrReadOnlyTransaction, err)
+	assert.Equal(t, ErrReadOnlyTransaction, child.FirstResultType(b, &struct{ ID int64 }{}))
+	_, err = child.Iterate(b)
+	assert.Equal(t, ErrReadOnlyTransaction, err)
+
+	b = Builder{}
+	b.Select("id").From("test").Build()
+
+	_, err = tx.Query(b)
+	assert.NoError(t, err)
+	assert.NoError(t, tx.Rollback())
+
+	assert.Equal(t, []string{
+		"BEGIN ISOLATION LEVEL SERIALIZABLE READ ONLY",
+		"SET TRANSACTION DEFERRABLE",
+		`SAVEPOINT "dal_savepoint_1"`,
+		"SELECT id FROM test",
+		"ROLLBACK"}, f.Statements())
+}
@@ -101,6 +101,10 @@ func (b *UpdateBuilder) Type(entity interface{}) *UpdateBuilder {
 			}
 		}
 
+		if config["sensitive"] {
+			value = Sensitive(value)
+		}
+
 		columnNames = append(columnNames, columnName)
 		b.SetParameter(count, value)
 		count += 1
@@ -114,12 +118,14 @@ func (b *UpdateBuilder) Type(entity interface{}) *UpdateBuilder {
 	return b
 }
 
-func (b *UpdateBuilder) Where(condition string) *UpdateBuilder {
-	return b.addWhere("AND", condition)
+//Where - adds a condition given as a string or a Cond, joined with AND
+func (b *UpdateBuilder) Where(condition interface{}) *UpdateBuilder {
+	return b.addWhere("AND", b.b.condition(condition))
 }
 
-func (b *UpdateBuilder) OrWhere(condition string) *UpdateBuilder {
-	return b.addWhere("OR", condition)
+//OrWhere - adds a condition given as a string or a Cond, joined with OR
+func (b *UpdateBuilder) OrWhere(condition interface{}) *UpdateBuilder {
+	return b.addWhere("OR", b.b.condition(condition))
 }
 
 func (b *UpdateBuilder) Build() {
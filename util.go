@@ -49,44 +49,93 @@ func structValue(m map[string]reflect.Value, value reflect.Value) {
 		t := value.Type()
 		for i := 0; i < t.NumField(); i++ {
 			field := t.Field(i)
-			if field.PkgPath != "" && !field.Anonymous {
-				// unexported
+			tagName, ok := fieldColumn(field)
+			if !ok {
 				continue
 			}
-			tag := field.Tag.Get("db")
-			tags := strings.Split(tag, ",")
-			tagName := ""
+			fieldValue := value.Field(i)
+			if _, ok := m[field.Tag.Get("db")]; !ok {
+				m[tagName] = fieldValue
+			}
+			structValue(m, fieldValue)
+		}
+	}
+}
+
+// fieldColumn returns the column of a struct field, from its db tag or its snake case name, ok is
+// false for the fields that are not loaded.
+func fieldColumn(field reflect.StructField) (string, bool) {
+	if field.PkgPath != "" && !field.Anonymous {
+		// unexported
+		return "", false
+	}
+	tag := field.Tag.Get("db")
+	tags := strings.Split(tag, ",")
+	tagName := ""
 
-			for _, t := range tags {
-				if strings.Contains(t, "=") {
-					split := strings.Split(t, "=")
+	for _, t := range tags {
+		if strings.Contains(t, "=") {
+			split := strings.Split(t, "=")
 
-					split[0] = strings.TrimSpace(split[0])
-					split[1] = strings.TrimSpace(split[1])
+			split[0] = strings.TrimSpace(split[0])
+			split[1] = strings.TrimSpace(split[1])
 
-					if strings.ToLower(split[0]) == "name" {
-						tagName = split[1]
-					}
-				}
+			if strings.ToLower(split[0]) == "name" {
+				tagName = split[1]
 			}
+		}
+	}
 
-			if tagName == "" {
-				tagName = strings.TrimSpace(tags[0])
-			}
+	if tagName == "" {
+		tagName = strings.TrimSpace(tags[0])
+	}
 
-			if tagName == "-" {
-				// ignore
+	if tagName == "-" {
+		// ignore
+		return "", false
+	}
+	if tagName == "" {
+		// no tag, but we can record the field name
+		tagName = camelCaseToSnakeCase(field.Name)
+	}
+	return tagName, true
+}
+
+// StructColumns returns the columns that QueryType loads into the fields of the struct v, with the
+// values of those fields, in the order of the fields. A field holding a struct, such as an embedded
+// one, gives the columns of its own fields instead.
+func StructColumns(v interface{}) ([]string, []interface{}) {
+	return appendStructColumns(nil, nil, reflect.ValueOf(v))
+}
+
+func appendStructColumns(columns []string, values []interface{}, value reflect.Value) ([]string, []interface{}) {
+	if !value.IsValid() || value.Type().Implements(typeValuer) {
+		return columns, values
+	}
+	switch value.Kind() {
+	case reflect.Ptr:
+		if !value.IsNil() {
+			return appendStructColumns(columns, values, value.Elem())
+		}
+	case reflect.Struct:
+		t := value.Type()
+		for i := 0; i < t.NumField(); i++ {
+			name, ok := fieldColumn(t.Field(i))
+			if !ok {
 				continue
 			}
-			if tagName == "" {
-				// no tag, but we can record the field name
-				tagName = camelCaseToSnakeCase(field.Name)
-			}
 			fieldValue := value.Field(i)
-			if _, ok := m[tag]; !ok {
-				m[tagName] = fieldValue
+			n := len(columns)
+			columns, values = appendStructColumns(columns, values, fieldValue)
+			if len(columns) == n && fieldValue.CanInterface() {
+				columns = append(columns, name)
+				values = append(values, fieldValue.Interface())
 			}
-			structValue(m, fieldValue)
 		}
 	}
+	return columns, values
+}
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
 }